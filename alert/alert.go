@@ -0,0 +1,375 @@
+// Package alert parses threshold rules such as "precip > 2mm within 6h" or
+// "temp < 0 tomorrow" and evaluates them against a forecast.
+package alert
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/render"
+)
+
+// Metric is the forecast value a rule tests
+type Metric string
+
+// Supported metrics
+const (
+	Temperature   Metric = "temp"
+	Precipitation Metric = "precip"
+	Wind          Metric = "wind"
+	Code          Metric = "code"
+)
+
+// metricNames maps the accepted spellings to metrics
+var metricNames = map[string]Metric{
+	"temp":          Temperature,
+	"temperature":   Temperature,
+	"precip":        Precipitation,
+	"precipitation": Precipitation,
+	"rain":          Precipitation,
+	"wind":          Wind,
+	"code":          Code,
+	"weather":       Code,
+	"condition":     Code,
+}
+
+// WindowKind says which part of the forecast a rule looks at
+type WindowKind int
+
+// Window kinds
+const (
+	Now   WindowKind = iota // the current conditions
+	Hours                   // the next Count hours of the hourly forecast
+	Days                    // Count days of the daily forecast from day Start
+)
+
+// Window is the stretch of forecast a rule looks at
+type Window struct {
+	Kind  WindowKind
+	Start int // first day, 0 being today; Days only
+	Count int // number of hours or days
+}
+
+// Rule is a parsed rule expression
+type Rule struct {
+	Text   string
+	Metric Metric
+	Op     string // >, >=, <, <=, ==, != or in
+	Value  float64
+	Codes  []int // WMO codes for "in"
+	Window Window
+}
+
+// The longest windows the forecast covers: providers return 24 hourly and 7
+// daily values
+const (
+	maxWindowHours = 24
+	maxWindowDays  = 7
+)
+
+// ruleRE splits a normalized rule into metric, operator, value and an
+// optional window
+var ruleRE = regexp.MustCompile(`^([a-z]+)\s*(>=|<=|==|!=|>|<|=|in\b)\s*(.+?)(?:\s+(now|today|tomorrow|(?:within|next)\s+(\d+)\s*(h|hours?|d|days?)))?$`)
+
+// valueRE splits a threshold into its number and unit
+var valueRE = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)\s*(°?[a-z/]*)$`)
+
+// Parse parses a rule such as "wind > 40km/h" or "code in thunderstorm
+// within 12h". Thresholds are converted to units; a threshold without a unit
+// is taken to be in units already. Without a window, temperature, wind and
+// code rules look at the current conditions and precipitation rules at the
+// next hour.
+func Parse(text string, units forecast.UnitSystem) (Rule, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	m := ruleRE.FindStringSubmatch(normalized)
+	if m == nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: expected <metric> <op> <value> [within Nh|within Nd|today|tomorrow]", text)
+	}
+
+	rule := Rule{Text: strings.TrimSpace(text), Op: m[2]}
+	metric, ok := metricNames[m[1]]
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid rule %q: unknown metric %q (use temp, precip, wind or code)", text, m[1])
+	}
+	rule.Metric = metric
+	if rule.Op == "=" {
+		rule.Op = "=="
+	}
+
+	if metric == Code {
+		if rule.Op != "in" {
+			return Rule{}, fmt.Errorf("invalid rule %q: use \"code in <conditions>\"", text)
+		}
+		codes, err := parseCodes(m[3])
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid rule %q: %w", text, err)
+		}
+		rule.Codes = codes
+	} else {
+		if rule.Op == "in" {
+			return Rule{}, fmt.Errorf("invalid rule %q: \"in\" only applies to code", text)
+		}
+		value, err := parseValue(metric, m[3], units)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid rule %q: %w", text, err)
+		}
+		rule.Value = value
+	}
+
+	window, err := parseWindow(m[4], m[5], m[6], metric)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: %w", text, err)
+	}
+	rule.Window = window
+	if rule.Metric == Wind && rule.Window.Kind != Now {
+		return Rule{}, fmt.Errorf("invalid rule %q: wind is only known for the current conditions", text)
+	}
+	return rule, nil
+}
+
+// parseWindow interprets the optional window part of a rule, rejecting
+// windows longer than the forecast, which would pass on partial data
+func parseWindow(window, count, unit string, metric Metric) (Window, error) {
+	n, _ := strconv.Atoi(count)
+	switch {
+	case window == "today":
+		return Window{Kind: Days, Start: 0, Count: 1}, nil
+	case window == "tomorrow":
+		return Window{Kind: Days, Start: 1, Count: 1}, nil
+	case strings.HasPrefix(unit, "h"):
+		if n < 1 || n > maxWindowHours {
+			return Window{}, fmt.Errorf("window must be 1 to %d hours", maxWindowHours)
+		}
+		return Window{Kind: Hours, Count: n}, nil
+	case strings.HasPrefix(unit, "d"):
+		if n < 1 || n > maxWindowDays {
+			return Window{}, fmt.Errorf("window must be 1 to %d days", maxWindowDays)
+		}
+		return Window{Kind: Days, Start: 0, Count: n}, nil
+	case metric == Precipitation:
+		// There is no current precipitation, so look at the coming hour
+		return Window{Kind: Hours, Count: 1}, nil
+	}
+	return Window{Kind: Now}, nil
+}
+
+// parseValue parses a threshold with an optional unit and converts it to units
+func parseValue(metric Metric, s string, units forecast.UnitSystem) (float64, error) {
+	m := valueRE.FindStringSubmatch(strings.ReplaceAll(s, " ", ""))
+	if m == nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	v, _ := strconv.ParseFloat(m[1], 64)
+	unit := strings.TrimPrefix(m[2], "°")
+	imperial := units == forecast.UnitImperial
+
+	switch metric {
+	case Temperature:
+		switch {
+		case unit == "":
+		case unit == "c" && imperial:
+			v = v*9/5 + 32
+		case unit == "f" && !imperial:
+			v = (v - 32) * 5 / 9
+		case unit != "c" && unit != "f":
+			return 0, fmt.Errorf("unknown temperature unit %q (use C or F)", m[2])
+		}
+	case Precipitation:
+		mm := map[string]float64{"mm": 1, "cm": 10, "in": 25.4}
+		if unit != "" {
+			factor, ok := mm[unit]
+			if !ok {
+				return 0, fmt.Errorf("unknown precipitation unit %q (use mm, cm or in)", m[2])
+			}
+			v *= factor
+			if imperial {
+				v /= 25.4
+			}
+		}
+	case Wind:
+		kmh := map[string]float64{"km/h": 1, "kmh": 1, "kph": 1, "mph": 1.609344, "m/s": 3.6, "kn": 1.852, "kt": 1.852}
+		if unit != "" {
+			factor, ok := kmh[unit]
+			if !ok {
+				return 0, fmt.Errorf("unknown wind speed unit %q (use km/h, mph, m/s or kn)", m[2])
+			}
+			v *= factor
+			if imperial {
+				v /= 1.609344
+			}
+		}
+	}
+	return v, nil
+}
+
+// parseCodes reads a comma-separated list of condition categories, such as
+// "rain" or "thunderstorm", and WMO codes
+func parseCodes(s string) ([]int, error) {
+	var codes []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if n, err := strconv.Atoi(part); err == nil {
+			codes = append(codes, n)
+			continue
+		}
+		found := false
+		for code := 0; code <= 99; code++ {
+			if render.WeatherCategory(code) == part {
+				codes = append(codes, code)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown condition %q (use clear, cloudy, fog, drizzle, rain, snow, thunderstorm or a WMO code)", part)
+		}
+	}
+	return codes, nil
+}
+
+// Result is the outcome of a rule for one forecast
+type Result struct {
+	Rule  Rule
+	Fired bool
+	Value float64 // the value that fired, or the closest one when none did
+	Code  int     // the weather code at Time
+	Time  string  // model time of Value: "2006-01-02T15:04" (UTC) or a date
+	Empty bool    // the forecast has no data for the window, or the provider does not report the metric
+}
+
+// point is one value of the window being tested
+type point struct {
+	value float64
+	code  int
+	time  string
+}
+
+// Eval tests the rule against weather, whose daily dates are calendar days
+// in zone, the location's time zone. now picks the current hour and, in zone,
+// the current day. Precipitation accumulates over the window, firing
+// when the running total crosses the threshold; other metrics fire at the
+// first point that meets it.
+func (r Rule) Eval(weather forecast.WeatherData, zone *time.Location, now time.Time) Result {
+	points := r.points(weather, zone, now)
+	res := Result{Rule: r, Empty: len(points) == 0}
+	if res.Empty {
+		return res
+	}
+
+	if r.Metric == Precipitation {
+		for i := 1; i < len(points); i++ {
+			points[i].value += points[i-1].value
+		}
+		// A total can only be below a threshold once the window is over
+		if r.Op != ">" && r.Op != ">=" {
+			points = points[len(points)-1:]
+		}
+	}
+
+	closest := points[0]
+	for _, p := range points {
+		if r.test(p) {
+			res.Fired, res.Value, res.Code, res.Time = true, p.value, p.code, p.time
+			return res
+		}
+		if (r.Op == "<" || r.Op == "<=") && p.value < closest.value ||
+			(r.Op == ">" || r.Op == ">=") && p.value > closest.value {
+			closest = p
+		}
+	}
+	res.Value, res.Code, res.Time = closest.value, closest.code, closest.time
+	return res
+}
+
+// test reports whether one point meets the rule
+func (r Rule) test(p point) bool {
+	switch r.Op {
+	case ">":
+		return p.value > r.Value
+	case ">=":
+		return p.value >= r.Value
+	case "<":
+		return p.value < r.Value
+	case "<=":
+		return p.value <= r.Value
+	case "==":
+		return p.value == r.Value
+	case "!=":
+		return p.value != r.Value
+	case "in":
+		for _, c := range r.Codes {
+			if p.code == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// points collects the values of the rule's metric within its window,
+// leaving out values the provider does not report
+func (r Rule) points(w forecast.WeatherData, zone *time.Location, now time.Time) []point {
+	var points []point
+	switch r.Window.Kind {
+	case Now:
+		c := w.CurrentWeather
+		value := c.Temperature
+		if r.Metric == Wind {
+			value = c.WindSpeed
+		}
+		if c.Time != "" {
+			points = append(points, point{value, c.WeatherCode, c.Time})
+		}
+
+	case Hours:
+		start := now.UTC().Truncate(time.Hour)
+		end := start.Add(time.Duration(r.Window.Count) * time.Hour)
+		for i, s := range w.Hourly.Time {
+			t, err := time.Parse("2006-01-02T15:04", s)
+			if err != nil || t.Before(start) || !t.Before(end) {
+				continue
+			}
+			value := w.Hourly.Temperature[i]
+			if r.Metric == Precipitation {
+				value = w.Hourly.Precipitation[i]
+			}
+			if math.IsNaN(value) {
+				continue
+			}
+			points = append(points, point{value, w.Hourly.WeatherCode[i], s})
+		}
+
+	case Days:
+		today := now.In(zone)
+		first := time.Date(today.Year(), today.Month(), today.Day()+r.Window.Start, 0, 0, 0, 0, zone).Format("2006-01-02")
+		last := time.Date(today.Year(), today.Month(), today.Day()+r.Window.Start+r.Window.Count-1, 0, 0, 0, 0, zone).Format("2006-01-02")
+		for i, day := range w.Daily.Time {
+			if day < first || day > last {
+				continue
+			}
+			// Highs decide whether it gets above a threshold, lows below
+			value := w.Daily.TemperatureMax[i]
+			if r.Op == "<" || r.Op == "<=" {
+				value = w.Daily.TemperatureMin[i]
+			}
+			if r.Metric == Precipitation {
+				value = w.Daily.PrecipitationSum[i]
+			}
+			if math.IsNaN(value) {
+				continue
+			}
+			points = append(points, point{value, w.Daily.WeatherCode[i], day})
+		}
+	}
+
+	if r.Metric == Code {
+		for i := range points {
+			points[i].value = float64(points[i].code)
+		}
+	}
+	return points
+}
@@ -0,0 +1,140 @@
+package alert
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text  string
+		units forecast.UnitSystem
+		want  Rule
+	}{
+		{"precip > 2mm within 6h", forecast.UnitMetric, Rule{Metric: Precipitation, Op: ">", Value: 2, Window: Window{Kind: Hours, Count: 6}}},
+		{"wind > 40km/h", forecast.UnitMetric, Rule{Metric: Wind, Op: ">", Value: 40, Window: Window{Kind: Now}}},
+		{"temp < 0 tomorrow", forecast.UnitMetric, Rule{Metric: Temperature, Op: "<", Value: 0, Window: Window{Kind: Days, Start: 1, Count: 1}}},
+		{"code in thunderstorm", forecast.UnitMetric, Rule{Metric: Code, Op: "in", Codes: []int{95, 96, 97, 98, 99}, Window: Window{Kind: Now}}},
+		{"Code in 45, 48 within 2 days", forecast.UnitMetric, Rule{Metric: Code, Op: "in", Codes: []int{45, 48}, Window: Window{Kind: Days, Count: 2}}},
+		{"temp >= 32F", forecast.UnitMetric, Rule{Metric: Temperature, Op: ">=", Value: 0, Window: Window{Kind: Now}}},
+		{"rain > 1 in within 12 hours", forecast.UnitMetric, Rule{Metric: Precipitation, Op: ">", Value: 25.4, Window: Window{Kind: Hours, Count: 12}}},
+		{"precip > 0", forecast.UnitMetric, Rule{Metric: Precipitation, Op: ">", Value: 0, Window: Window{Kind: Hours, Count: 1}}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.text, tt.units)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		tt.want.Text = tt.text
+		if math.Abs(got.Value-tt.want.Value) < 1e-9 {
+			got.Value = tt.want.Value
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v; want %+v", tt.text, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "humidity > 50", "temp > warm", "wind > 40 within 6h", "code > 3", "temp in rain", "wind > 4 furlongs",
+		"precip > 2mm within 48h", "precip > 2mm within 0h", "temp < 0 within 0d", "temp < 0 within 10 days"} {
+		if _, err := Parse(bad, forecast.UnitMetric); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.CurrentWeather.Time = "2024-05-01T10:00"
+	weather.CurrentWeather.Temperature = 12
+	weather.CurrentWeather.WindSpeed = 45
+	weather.CurrentWeather.WeatherCode = 3
+	weather.Hourly.Time = []string{"2024-05-01T09:00", "2024-05-01T10:00", "2024-05-01T11:00", "2024-05-01T12:00"}
+	weather.Hourly.Temperature = []float64{10, 12, 14, 15}
+	weather.Hourly.Precipitation = []float64{5, 1, 1.5, 3}
+	weather.Hourly.WeatherCode = []int{61, 61, 80, 95}
+	weather.Daily.Time = []string{"2024-05-01", "2024-05-02"}
+	weather.Daily.TemperatureMax = []float64{16, 8}
+	weather.Daily.TemperatureMin = []float64{4, -2}
+	weather.Daily.PrecipitationSum = []float64{10, 0}
+	weather.Daily.WeatherCode = []int{95, 3}
+
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		rule  string
+		fired bool
+		value float64
+		time  string
+	}{
+		// 1 + 1.5 crosses 2mm at 11:00; the 09:00 hour is already past
+		{"precip > 2mm within 6h", true, 2.5, "2024-05-01T11:00"},
+		{"precip < 5 within 3h", false, 5.5, "2024-05-01T12:00"},
+		{"wind > 40km/h", true, 45, "2024-05-01T10:00"},
+		{"wind > 50", false, 45, "2024-05-01T10:00"},
+		{"temp < 0 tomorrow", true, -2, "2024-05-02"},
+		{"temp > 20 within 2d", false, 16, "2024-05-01"},
+		{"code in thunderstorm within 3h", true, 95, "2024-05-01T12:00"},
+		{"code in snow today", false, 95, "2024-05-01"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule, forecast.UnitMetric)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.rule, err)
+		}
+		got := rule.Eval(weather, time.UTC, now)
+		if got.Fired != tt.fired || got.Value != tt.value || got.Time != tt.time {
+			t.Errorf("%q = fired %v, %v at %s; want %v, %v at %s", tt.rule, got.Fired, got.Value, got.Time, tt.fired, tt.value, tt.time)
+		}
+	}
+
+	// Providers such as NWS report no precipitation amounts
+	for i := range weather.Hourly.Precipitation {
+		weather.Hourly.Precipitation[i] = math.NaN()
+	}
+	weather.Daily.PrecipitationSum = []float64{math.NaN(), math.NaN()}
+	for _, text := range []string{"precip > 2mm within 6h", "precip < 1 today"} {
+		rule, _ := Parse(text, forecast.UnitMetric)
+		if got := rule.Eval(weather, time.UTC, now); got.Fired || !got.Empty {
+			t.Errorf("%q without precipitation = %+v; want empty", text, got)
+		}
+	}
+}
+
+func TestEvalLocalDays(t *testing.T) {
+	zone, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	var weather forecast.WeatherData
+	weather.Daily.Time = []string{"2024-05-01", "2024-05-02", "2024-05-03"}
+	weather.Daily.TemperatureMax = []float64{20, 25, 10}
+	weather.Daily.TemperatureMin = []float64{8, 12, 2}
+	weather.Daily.PrecipitationSum = []float64{0, 0, 0}
+	weather.Daily.WeatherCode = []int{0, 0, 0}
+
+	// 23:30 in New York is already May 2 in UTC
+	now := time.Date(2024, 5, 1, 23, 30, 0, 0, zone)
+	tests := []struct {
+		rule  string
+		fired bool
+		time  string
+	}{
+		{"temp > 22 today", false, "2024-05-01"},
+		{"temp > 22 tomorrow", true, "2024-05-02"},
+		{"temp < 5 within 2d", false, "2024-05-01"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule, forecast.UnitMetric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := rule.Eval(weather, zone, now)
+		if got.Fired != tt.fired || got.Time != tt.time {
+			t.Errorf("%q = fired %v at %s; want %v at %s", tt.rule, got.Fired, got.Time, tt.fired, tt.time)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for DefaultClient
+const (
+	DefaultTimeout    = 15 * time.Second
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 10 * time.Second
+	DefaultUserAgent  = "go-weather (https://github.com/streek/go-weather)"
+)
+
+// Client performs HTTP requests with a per-attempt timeout and retries
+// transient failures (network errors, 429 and 5xx responses) with
+// exponential backoff and jitter. It is safe for concurrent use.
+type Client struct {
+	HTTPClient *http.Client
+	UserAgent  string
+	MaxRetries int           // retries after the first attempt
+	BaseDelay  time.Duration // delay before the first retry, doubled each time
+	MaxDelay   time.Duration // upper bound for any single delay
+
+	// Retry decides which failed attempts to repeat; nil retries network
+	// errors, 429 and 5xx responses
+	Retry func(resp *Response, err error) bool
+}
+
+// DefaultClient is shared by the geocoder and forecast providers unless they
+// are given their own
+var DefaultClient = NewClient()
+
+// NewClient returns a Client with the default timeout and retry policy
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		UserAgent:  DefaultUserAgent,
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// Response is a completed request with its body already read
+type Response struct {
+	*http.Response
+	Body []byte
+}
+
+// Get fetches url, retrying transient failures until ctx is done. A response
+// is returned for any HTTP status; use CheckResponse to reject errors.
+func (c *Client) Get(ctx context.Context, url string, header http.Header) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, url, header, nil)
+}
+
+// Post sends body to url with the same retry policy as Get, so it should
+// only be used for writes that are safe to repeat, unless Retry is set to
+// RetryUnsent
+func (c *Client) Post(ctx context.Context, url string, header http.Header, body []byte) (*Response, error) {
+	return c.Do(ctx, http.MethodPost, url, header, body)
+}
+
+// Do performs a request, retrying transient failures until ctx is done
+func (c *Client) Do(ctx context.Context, method, url string, header http.Header, body []byte) (*Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, method, url, header, body)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		retry := c.Retry
+		if retry == nil {
+			retry = retryable
+		}
+		if attempt >= c.MaxRetries || !retry(resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = after
+			}
+		}
+		if delay > c.MaxDelay {
+			delay = c.MaxDelay
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// do performs a single attempt
+func (c *Client) do(ctx context.Context, method, url string, header http.Header, body []byte) (*Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("User-Agent") == "" && c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+	return &Response{Response: resp, Body: data}, nil
+}
+
+// retryable reports whether an attempt failed in a way worth retrying
+func retryable(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// RetryUnsent retries only failures that show the server never acted on the
+// request: a connection that could not be made, or a 429 response. A timeout
+// or 5xx may come after the request took effect, so writes that must not be
+// repeated, such as chat messages, should use it as Client.Retry.
+func RetryUnsent(resp *Response, err error) bool {
+	if err != nil {
+		var op *net.OpError
+		return errors.As(err, &op) && op.Op == "dial"
+	}
+	return resp.StatusCode == http.StatusTooManyRequests
+}
+
+// backoff returns the delay before retry number attempt+1: the base delay
+// doubled per attempt, with half of it randomized to spread out clients
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + randInt63n(half))
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+var (
+	jitterMu sync.Mutex
+	jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randInt63n(n int64) int64 {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return jitter.Int63n(n)
+}
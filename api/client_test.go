@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.BaseDelay = time.Millisecond
+	resp, err := client.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("status %d after %d requests; want 200 after 3", resp.StatusCode, requests)
+	}
+}
+
+func TestClientDoesNotRetryBadRequest(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.BaseDelay = time.Millisecond
+	resp, err := client.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest || requests != 1 {
+		t.Errorf("status %d after %d requests; want 400 after 1", resp.StatusCode, requests)
+	}
+}
+
+func TestRetryUnsent(t *testing.T) {
+	// Nothing listens on a closed server's port
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client := NewClient()
+	client.MaxRetries = 0
+	_, refused := client.Get(context.Background(), server.URL, nil)
+
+	tests := []struct {
+		name string
+		resp *Response
+		err  error
+		want bool
+	}{
+		{"refused", nil, refused, true},
+		{"429", &Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests}}, nil, true},
+		{"503", &Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}, nil, false},
+		{"timeout", nil, context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := RetryUnsent(tt.resp, tt.err); got != tt.want {
+			t.Errorf("RetryUnsent(%s: %v) = %v; want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClientTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient()
+	client.HTTPClient.Timeout = 20 * time.Millisecond
+	client.BaseDelay = time.Hour // a retry would outlast the context
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Get(ctx, server.URL, nil); err == nil {
+		t.Fatal("Get succeeded against a stalled server")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get took %v; want it bounded by the context", elapsed)
+	}
+}
@@ -0,0 +1,83 @@
+// Package api holds the HTTP plumbing shared by the geocoding and forecast
+// clients.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error categories, matched with errors.Is
+var (
+	ErrBadRequest  = errors.New("bad request")
+	ErrRateLimited = errors.New("rate limited")
+	ErrServer      = errors.New("server error")
+)
+
+// Error is a failed API response
+type Error struct {
+	StatusCode int
+	Reason     string // explanation from the response body, if any
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.category(), e.Reason, status)
+	}
+	return fmt.Sprintf("%s (%s)", e.category(), status)
+}
+
+// Unwrap returns the error category so errors.Is can match it
+func (e *Error) Unwrap() error {
+	return e.category()
+}
+
+func (e *Error) category() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	default:
+		return ErrBadRequest
+	}
+}
+
+// CheckResponse returns an *Error for non-2xx responses and for Open-Meteo's
+// {"error":true,"reason":...} bodies, and nil otherwise
+func CheckResponse(resp *http.Response, body []byte) error {
+	// Open-Meteo, NWS (problem+json) and MET Norway explain errors
+	// differently, as do the InfluxDB and webhook endpoints we write to
+	var problem struct {
+		Error   bool   `json:"error"`
+		Reason  string `json:"reason"`
+		Detail  string `json:"detail"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &problem)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		if !problem.Error {
+			return nil
+		}
+		// An error body without an error status is still a rejected request
+		return &Error{StatusCode: http.StatusBadRequest, Reason: problem.Reason}
+	}
+
+	reason := problem.Reason
+	if reason == "" {
+		reason = problem.Detail
+	}
+	if reason == "" {
+		reason = problem.Message
+	}
+	if reason == "" && !strings.HasPrefix(strings.TrimSpace(string(body)), "{") && len(body) < 200 {
+		reason = strings.TrimSpace(string(body))
+	}
+	return &Error{StatusCode: resp.StatusCode, Reason: reason}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		reason string
+	}{
+		{"ok", 200, `{"latitude":1}`, nil, ""},
+		{"open-meteo bad request", 400, `{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`, ErrBadRequest, "Latitude must be in range of -90 to 90°."},
+		{"error body with 200", 200, `{"error":true,"reason":"Invalid"}`, ErrBadRequest, "Invalid"},
+		{"rate limited", 429, `{"error":true,"reason":"Daily API request limit exceeded"}`, ErrRateLimited, "Daily API request limit exceeded"},
+		{"nws problem", 500, `{"title":"Unexpected Problem","detail":"An unexpected problem has occurred."}`, ErrServer, "An unexpected problem has occurred."},
+		{"plain text", 503, "Service Unavailable", ErrServer, "Service Unavailable"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckResponse(&http.Response{StatusCode: tc.status}, []byte(tc.body))
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("CheckResponse = %v; want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("CheckResponse = %v; want %v", err, tc.want)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.Reason != tc.reason {
+				t.Errorf("reason = %q; want %q", apiErr.Reason, tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package cache stores API responses on disk for a limited time.
+package cache
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache is a directory of JSON files that expire after TTL
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// entry is the on-disk structure with timestamp and data
+type entry struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// New returns a Cache rooted at dir
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// DefaultDir returns the default cache directory
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "weather-cache")
+}
+
+// Key hashes an arbitrary description of a request into a file-safe key
+func Key(s string) string {
+	hash := md5.Sum([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
+
+// Get decodes a fresh entry for key into v and reports whether one existed
+func (c *Cache) Get(key string, v interface{}) bool {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+
+	// Check if cache is still valid
+	if time.Since(e.Timestamp) > c.TTL {
+		return false
+	}
+
+	return json.Unmarshal(e.Data, v) == nil
+}
+
+// Expires returns when the entry for key stops being fresh, if there is one
+func (c *Cache) Expires(key string) (time.Time, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, false
+	}
+	return e.Timestamp.Add(c.TTL), true
+}
+
+// Put stores v under key
+func (c *Cache) Put(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	cacheData, err := json.Marshal(entry{
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), cacheData, 0644)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// Delete removes the entry for key, if any
+func (c *Cache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Clear removes every entry and returns how many were removed.
+// Subdirectories, which may hold other caches, are left alone.
+func (c *Cache) Clear() (int, error) {
+	paths, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	for i, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return i, err
+		}
+	}
+	return len(paths), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+// runCache inspects and invalidates the on-disk caches
+func runCache(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: cache clear [all|forecast|geocode] or cache forget <location>")
+	}
+
+	config := loadConfig()
+	client := newClient(config)
+	// MET Norway keeps its last responses apart from the forecast cache
+	metNorway := cache.New(forecast.METNorwayCacheDir(), 0)
+	switch args[0] {
+	case "clear":
+		what := "all"
+		if len(args) > 1 {
+			what = args[1]
+		}
+		var caches []*cache.Cache
+		switch what {
+		case "all":
+			caches = []*cache.Cache{client.Cache, metNorway, client.GeoCache}
+		case "forecast":
+			caches = []*cache.Cache{client.Cache, metNorway}
+		case "geocode":
+			caches = []*cache.Cache{client.GeoCache}
+		default:
+			return fmt.Errorf("unknown cache %q (use all, forecast or geocode)", what)
+		}
+
+		removed := 0
+		for _, c := range caches {
+			n, err := c.Clear()
+			removed += n
+			if err != nil {
+				return fmt.Errorf("error clearing cache: %w", err)
+			}
+		}
+		fmt.Printf("Removed %d cached entries\n", removed)
+		return nil
+
+	case "forget":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: cache forget <location>")
+		}
+		query := strings.Join(args[1:], " ")
+		if err := client.ForgetLocation(query); err != nil {
+			return fmt.Errorf("error clearing cache: %w", err)
+		}
+		// A place picked for an ambiguous query would still be used
+		key := geocode.NormalizeQuery(query)
+		if _, ok := config.Choices[key]; ok {
+			delete(config.Choices, key)
+			if err := saveConfig(config); err != nil {
+				return fmt.Errorf("error saving config: %w", err)
+			}
+		}
+		fmt.Printf("Forgot cached location for %q\n", query)
+		return nil
+	}
+	return fmt.Errorf("unknown cache command %q (use clear or forget)", args[0])
+}
+
+// printCacheHelp lists the cache commands in the main help text
+func printCacheHelp() {
+	fmt.Printf("  cache clear [all|forecast|geocode]     Empty the forecast and/or location cache\n")
+	fmt.Printf("  cache forget <location>                Drop one cached location lookup\n\n")
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/streek/go-weather/alert"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/render"
+)
+
+// runCheck evaluates alert rules against the forecast, exiting with 1 when
+// any of them fires. Failures without an exit code of their own exit with 2
+// rather than 1.
+func runCheck(ctx context.Context, args []string) error {
+	err := check(ctx, args)
+	if err != nil && exitCode(err) == exitError {
+		return codeError{err, exitCheckError}
+	}
+	return err
+}
+
+// check runs the check command
+func check(ctx context.Context, args []string) error {
+	config := loadConfig()
+
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	var zips locationList
+	fs.Var(&zips, "zip", "Locations to check; repeat or separate with ;")
+	fs.Var(&zips, "z", "Short for -zip")
+	units := fs.String("units", "", "Units for thresholds without a unit and for the output")
+	quiet := fs.Bool("quiet", false, "Only print rules that fired")
+	cmd := &Command{retries: -1}
+	fs.StringVar(&cmd.provider, "provider", "", "Weather data provider(s), comma-separated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: check [-zip location] [-units system] [-quiet] <rule> [<rule>...]")
+	}
+
+	unitSystem := forecast.UnitSystem(*units)
+	if unitSystem == "" {
+		unitSystem = config.Units
+	}
+	if unitSystem == "" {
+		unitSystem = forecast.UnitMetric
+	}
+	var rules []alert.Rule
+	for _, arg := range fs.Args() {
+		rule, err := alert.Parse(arg, unitSystem)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+
+	provider, err := cmd.newProvider(config)
+	if err != nil {
+		return err
+	}
+	if err := configureHTTP(config, cmd); err != nil {
+		return err
+	}
+	client := newClient(config)
+	client.Provider = provider
+
+	queries := config.expandLocations(zips)
+	if len(queries) == 0 {
+		queries = config.expandLocations(splitLocations(config.ZipCode))
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no location given; use -zip or save a default location")
+	}
+	opts := forecast.Options{Daily: true, Hourly: true, Units: unitSystem}
+	sections, err := fetchSections(ctx, client, &config, queries, opts, io.Discard, false, false)
+	if err != nil {
+		return err
+	}
+
+	results := evalRules(rules, sections, time.Now())
+	fired := false
+	for _, r := range results {
+		fired = fired || r.Fired
+		if r.Fired || !*quiet {
+			fmt.Println(describeResult(r, len(sections) > 1, unitSystem))
+		}
+	}
+	if fired {
+		return exitStatus(exitRuleFired)
+	}
+	return nil
+}
+
+// ruleResult is a rule's outcome for one location
+type ruleResult struct {
+	alert.Result
+	section render.Section
+}
+
+// evalRules tests every rule against every section
+func evalRules(rules []alert.Rule, sections []render.Section, now time.Time) []ruleResult {
+	var results []ruleResult
+	for _, s := range sections {
+		for _, rule := range rules {
+			results = append(results, ruleResult{rule.Eval(s.Weather, s.Location.Zone(), now), s})
+		}
+	}
+	return results
+}
+
+// describeResult formats one outcome, e.g.
+// "FIRED precip > 2mm within 6h: 2.5mm by Wed 14:00"
+func describeResult(r ruleResult, showLocation bool, units forecast.UnitSystem) string {
+	status := "ok   "
+	if r.Fired {
+		status = "FIRED"
+	}
+	line := status + " " + r.Rule.Text
+	if showLocation {
+		line += " [" + r.section.Label + "]"
+	}
+	return line + ": " + resultOutcome(r, units)
+}
+
+// resultOutcome gives the value behind a result and when it applies, e.g.
+// "2.5mm by Wed 14:00"
+func resultOutcome(r ruleResult, units forecast.UnitSystem) string {
+	if r.Empty {
+		return "no forecast data for this window"
+	}
+
+	var value string
+	switch r.Rule.Metric {
+	case alert.Temperature:
+		value = fmt.Sprintf("%.1f%s", r.Value, forecast.TempUnit(units))
+	case alert.Wind:
+		value = fmt.Sprintf("%.1f %s", r.Value, forecast.WindUnit(units))
+	case alert.Precipitation:
+		value = fmt.Sprintf("%.1f%s", r.Value, forecast.PrecipUnit(units))
+	case alert.Code:
+		value = fmt.Sprintf("%s (%d)", render.WeatherDescription(r.Code), r.Code)
+	}
+	return value + " " + resultTime(r)
+}
+
+// resultTime says when a result applies, in the location's time zone
+func resultTime(r ruleResult) string {
+	zone := r.section.Location.Zone()
+	if r.Rule.Window.Kind == alert.Now {
+		return "now"
+	}
+	if t, err := time.Parse("2006-01-02T15:04", r.Time); err == nil {
+		if r.Rule.Metric == alert.Precipitation {
+			return "by " + t.In(zone).Format("Mon 15:04")
+		}
+		return "at " + t.In(zone).Format("Mon 15:04")
+	}
+	if t, err := time.Parse("2006-01-02", r.Time); err == nil {
+		return "on " + t.Format("Mon Jan 2")
+	}
+	return r.Time
+}
+
+// printCheckHelp lists the check command in the main help text
+func printCheckHelp() {
+	fmt.Printf("  check [-zip loc] [-quiet] <rule>...    Exit 1 if a rule such as \"wind > 40km/h\",\n")
+	fmt.Printf("                                         \"precip > 2mm within 6h\", \"temp < 0 tomorrow\"\n")
+	fmt.Printf("                                         or \"code in thunderstorm\" fires, else 0;\n")
+	fmt.Printf("                                         2 on errors such as an invalid rule\n\n")
+}
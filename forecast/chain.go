@@ -0,0 +1,85 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Chain is a Provider that tries each of its providers in order and returns
+// the first successful forecast. Any error, including timeouts and non-2xx
+// responses, moves on to the next provider.
+type Chain struct {
+	Providers []Provider
+	Log       io.Writer // receives a line per failed provider; nil discards them
+}
+
+// NewChain builds a provider from an ordered list of names. A single name
+// returns that provider directly.
+func NewChain(names []string) (Provider, error) {
+	if len(names) == 0 {
+		return NewProvider(DefaultProvider)
+	}
+
+	chain := &Chain{}
+	for _, name := range names {
+		provider, err := NewProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		chain.Providers = append(chain.Providers, provider)
+	}
+	if len(chain.Providers) == 1 {
+		return chain.Providers[0], nil
+	}
+	return chain, nil
+}
+
+// Name implements Provider
+func (c *Chain) Name() string {
+	names := make([]string, len(c.Providers))
+	for i, provider := range c.Providers {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Fetch implements Provider. The returned data's Source names the provider
+// that answered.
+func (c *Chain) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	var failures []providerError
+	for i, provider := range c.Providers {
+		weather, err := provider.Fetch(ctx, lat, lon, opts)
+		if err == nil {
+			weather.Source = provider.Name()
+			return weather, nil
+		}
+
+		failures = append(failures, providerError{provider.Name(), err})
+		if c.Log != nil && i+1 < len(c.Providers) {
+			fmt.Fprintf(c.Log, "Warning: %s failed (%v), trying %s\n", provider.Name(), err, c.Providers[i+1].Name())
+		}
+	}
+	return WeatherData{}, allFailed(failures)
+}
+
+// providerError is one provider's failure
+type providerError struct {
+	name string
+	err  error
+}
+
+// allFailed summarizes provider failures, wrapping the last one so its
+// category stays visible to errors.Is
+func allFailed(failures []providerError) error {
+	if len(failures) == 0 {
+		return fmt.Errorf("no providers configured")
+	}
+	var earlier []string
+	for _, f := range failures[:len(failures)-1] {
+		earlier = append(earlier, fmt.Sprintf("%s: %v; ", f.name, f.err))
+	}
+	last := failures[len(failures)-1]
+	return fmt.Errorf("all providers failed: %s%s: %w", strings.Join(earlier, ""), last.name, last.err)
+}
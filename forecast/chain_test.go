@@ -0,0 +1,64 @@
+package forecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/api"
+)
+
+// stubProvider returns a fixed result
+type stubProvider struct {
+	name    string
+	weather WeatherData
+	err     error
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	return p.weather, p.err
+}
+
+func TestChainFailsOver(t *testing.T) {
+	// A real Open-Meteo provider pointed at a server that is down for maintenance
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	openMeteo := NewOpenMeteo()
+	openMeteo.BaseURL = server.URL
+	openMeteo.HTTP = api.NewClient()
+	openMeteo.HTTP.MaxRetries = 0
+
+	backup := &stubProvider{name: "backup"}
+	backup.weather.CurrentWeather.Temperature = 7
+
+	var log strings.Builder
+	chain := &Chain{Providers: []Provider{openMeteo, backup}, Log: &log}
+	weather, err := chain.Fetch(context.Background(), 1, 2, Options{})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if weather.Source != "backup" || weather.CurrentWeather.Temperature != 7 {
+		t.Errorf("got %+v from %q; want backup data", weather.CurrentWeather, weather.Source)
+	}
+	if !strings.Contains(log.String(), "open-meteo failed") {
+		t.Errorf("log = %q; want open-meteo failure noted", log.String())
+	}
+}
+
+func TestChainAllFail(t *testing.T) {
+	chain := &Chain{Providers: []Provider{
+		&stubProvider{name: "a", err: errors.New("down")},
+		&stubProvider{name: "b", err: errors.New("timeout")},
+	}}
+	_, err := chain.Fetch(context.Background(), 1, 2, Options{})
+	if err == nil || !strings.Contains(err.Error(), "a: down") || !strings.Contains(err.Error(), "b: timeout") {
+		t.Errorf("err = %v; want both failures listed", err)
+	}
+}
@@ -0,0 +1,325 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Consensus methods
+const (
+	ConsensusMedian = "median"
+	ConsensusMean   = "mean"
+)
+
+// Range is the lowest and highest value reported across providers. Both are
+// NaN when no provider reported the value, which encodes as JSON null.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Missing reports whether no provider reported the value
+func (r Range) Missing() bool {
+	return math.IsNaN(r.Min)
+}
+
+// MarshalJSON implements json.Marshaler
+func (r Range) MarshalJSON() ([]byte, error) {
+	if r.Missing() {
+		return []byte("null"), nil
+	}
+	type plain Range
+	return json.Marshal(plain(r))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *Range) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*r = Range{Min: math.NaN(), Max: math.NaN()}
+		return nil
+	}
+	type plain Range
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// Spread records how far providers disagree for each blended value. Slices
+// line up with the corresponding WeatherData series.
+type Spread struct {
+	Method    string   `json:"method"`
+	Providers []string `json:"providers"`
+	Current   struct {
+		Temperature Range `json:"temperature"`
+		WindSpeed   Range `json:"windspeed"`
+	} `json:"current_weather"`
+	Daily struct {
+		TemperatureMax   []Range `json:"temperature_2m_max"`
+		TemperatureMin   []Range `json:"temperature_2m_min"`
+		PrecipitationSum []Range `json:"precipitation_sum"`
+	} `json:"daily"`
+	Hourly struct {
+		Temperature   []Range `json:"temperature_2m"`
+		Precipitation []Range `json:"precipitation"`
+	} `json:"hourly"`
+}
+
+// Consensus is a Provider that queries all its providers concurrently and
+// blends their forecasts into a median or mean, recording the spread.
+// Providers that fail are left out of the blend.
+type Consensus struct {
+	Providers []Provider
+	Method    string    // ConsensusMedian (default) or ConsensusMean
+	Log       io.Writer // receives a line per failed provider; nil discards them
+}
+
+// NewConsensus builds a consensus over the named providers, or over every
+// registered provider if names is empty
+func NewConsensus(names []string, method string) (*Consensus, error) {
+	if len(names) == 0 {
+		names = ProviderNames()
+	}
+	switch method {
+	case "":
+		method = ConsensusMedian
+	case ConsensusMedian, ConsensusMean:
+	default:
+		return nil, fmt.Errorf("unknown consensus method %q (use median or mean)", method)
+	}
+
+	consensus := &Consensus{Method: method}
+	for _, name := range names {
+		provider, err := NewProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		consensus.Providers = append(consensus.Providers, provider)
+	}
+	return consensus, nil
+}
+
+// Name implements Provider
+func (c *Consensus) Name() string {
+	names := make([]string, len(c.Providers))
+	for i, provider := range c.Providers {
+		names[i] = provider.Name()
+	}
+	return fmt.Sprintf("consensus-%s(%s)", c.method(), strings.Join(names, ","))
+}
+
+func (c *Consensus) method() string {
+	if c.Method == "" {
+		return ConsensusMedian
+	}
+	return c.Method
+}
+
+// Fetch implements Provider
+func (c *Consensus) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	results := make([]WeatherData, len(c.Providers))
+	errs := make([]error, len(c.Providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range c.Providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			results[i], errs[i] = provider.Fetch(ctx, lat, lon, opts)
+		}(i, provider)
+	}
+	wg.Wait()
+
+	var ok []WeatherData
+	var names []string
+	var failures []providerError
+	for i, provider := range c.Providers {
+		if errs[i] != nil {
+			failures = append(failures, providerError{provider.Name(), errs[i]})
+			if c.Log != nil {
+				fmt.Fprintf(c.Log, "Warning: %s failed (%v), leaving it out of the consensus\n", provider.Name(), errs[i])
+			}
+			continue
+		}
+		ok = append(ok, results[i])
+		names = append(names, provider.Name())
+	}
+	if len(ok) == 0 {
+		return WeatherData{}, allFailed(failures)
+	}
+
+	weather := blend(ok, c.method())
+	weather.Source = strings.Join(names, "+")
+	weather.Spread.Method = c.method()
+	weather.Spread.Providers = names
+	return weather, nil
+}
+
+// blend merges forecasts, matching series entries by their time
+func blend(forecasts []WeatherData, method string) WeatherData {
+	var weather WeatherData
+	spread := &Spread{}
+	weather.Spread = spread
+
+	var temps, winds []float64
+	var codes []int
+	for _, f := range forecasts {
+		temps = append(temps, f.CurrentWeather.Temperature)
+		winds = append(winds, f.CurrentWeather.WindSpeed)
+		codes = append(codes, f.CurrentWeather.WeatherCode)
+	}
+	weather.CurrentWeather.Time = forecasts[0].CurrentWeather.Time
+	weather.CurrentWeather.Temperature, spread.Current.Temperature = combine(temps, method)
+	weather.CurrentWeather.WindSpeed, spread.Current.WindSpeed = combine(winds, method)
+	weather.CurrentWeather.WeatherCode = consensusCode(codes)
+
+	// Daily series
+	days := map[string]*dailySamples{}
+	var dayKeys []string
+	for _, f := range forecasts {
+		for i, day := range f.Daily.Time {
+			d, ok := days[day]
+			if !ok {
+				d = &dailySamples{}
+				days[day] = d
+				dayKeys = append(dayKeys, day)
+			}
+			d.max = append(d.max, valueAt(f.Daily.TemperatureMax, i))
+			d.min = append(d.min, valueAt(f.Daily.TemperatureMin, i))
+			d.precip = append(d.precip, valueAt(f.Daily.PrecipitationSum, i))
+			d.codes = append(d.codes, codeAt(f.Daily.WeatherCode, i))
+		}
+	}
+	// ISO dates and times sort chronologically as strings
+	sort.Strings(dayKeys)
+	for _, day := range dayKeys {
+		d := days[day]
+		max, maxRange := combine(d.max, method)
+		min, minRange := combine(d.min, method)
+		precip, precipRange := combine(d.precip, method)
+		if math.IsNaN(max) || math.IsNaN(min) {
+			continue // only in series cut short
+		}
+		weather.Daily.Time = append(weather.Daily.Time, day)
+		weather.Daily.TemperatureMax = append(weather.Daily.TemperatureMax, max)
+		weather.Daily.TemperatureMin = append(weather.Daily.TemperatureMin, min)
+		weather.Daily.PrecipitationSum = append(weather.Daily.PrecipitationSum, precip)
+		weather.Daily.WeatherCode = append(weather.Daily.WeatherCode, consensusCode(d.codes))
+		spread.Daily.TemperatureMax = append(spread.Daily.TemperatureMax, maxRange)
+		spread.Daily.TemperatureMin = append(spread.Daily.TemperatureMin, minRange)
+		spread.Daily.PrecipitationSum = append(spread.Daily.PrecipitationSum, precipRange)
+	}
+
+	// Hourly series
+	hours := map[string]*hourlySamples{}
+	var hourKeys []string
+	for _, f := range forecasts {
+		for i, hour := range f.Hourly.Time {
+			h, ok := hours[hour]
+			if !ok {
+				h = &hourlySamples{}
+				hours[hour] = h
+				hourKeys = append(hourKeys, hour)
+			}
+			h.temp = append(h.temp, valueAt(f.Hourly.Temperature, i))
+			h.precip = append(h.precip, valueAt(f.Hourly.Precipitation, i))
+			h.codes = append(h.codes, codeAt(f.Hourly.WeatherCode, i))
+		}
+	}
+	sort.Strings(hourKeys)
+	for _, hour := range hourKeys {
+		h := hours[hour]
+		temp, tempRange := combine(h.temp, method)
+		precip, precipRange := combine(h.precip, method)
+		if math.IsNaN(temp) {
+			continue
+		}
+		weather.Hourly.Time = append(weather.Hourly.Time, hour)
+		weather.Hourly.Temperature = append(weather.Hourly.Temperature, temp)
+		weather.Hourly.Precipitation = append(weather.Hourly.Precipitation, precip)
+		weather.Hourly.WeatherCode = append(weather.Hourly.WeatherCode, consensusCode(h.codes))
+		spread.Hourly.Temperature = append(spread.Hourly.Temperature, tempRange)
+		spread.Hourly.Precipitation = append(spread.Hourly.Precipitation, precipRange)
+	}
+
+	return weather
+}
+
+// valueAt returns values[i], or NaN (missing) past the end of a series that
+// is shorter than its times
+func valueAt(values []float64, i int) float64 {
+	if i >= len(values) {
+		return math.NaN()
+	}
+	return values[i]
+}
+
+// codeAt returns codes[i], or -1 (unknown) past the end of a short series
+func codeAt(codes []int, i int) int {
+	if i >= len(codes) {
+		return -1
+	}
+	return codes[i]
+}
+
+// dailySamples collects each provider's values for one day
+type dailySamples struct {
+	max, min, precip []float64
+	codes            []int
+}
+
+// hourlySamples collects each provider's values for one hour
+type hourlySamples struct {
+	temp, precip []float64
+	codes        []int
+}
+
+// combine returns the median or mean of values and their range, leaving out
+// missing (NaN) values. It returns NaN when every value is missing.
+func combine(values []float64, method string) (float64, Range) {
+	var sorted []float64
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) == 0 {
+		return math.NaN(), Range{Min: math.NaN(), Max: math.NaN()}
+	}
+	sort.Float64s(sorted)
+	r := Range{Min: sorted[0], Max: sorted[len(sorted)-1]}
+
+	if method == ConsensusMean {
+		var sum float64
+		for _, v := range sorted {
+			sum += v
+		}
+		return sum / float64(len(sorted)), r
+	}
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2, r
+	}
+	return sorted[mid], r
+}
+
+// consensusCode picks the most common weather code, preferring the more
+// severe (higher) code on ties and ignoring unknown codes
+func consensusCode(codes []int) int {
+	counts := map[int]int{}
+	best, bestCount := -1, 0
+	for _, code := range codes {
+		if code < 0 {
+			continue
+		}
+		counts[code]++
+		if counts[code] > bestCount || counts[code] == bestCount && code > best {
+			best, bestCount = code, counts[code]
+		}
+	}
+	return best
+}
@@ -0,0 +1,122 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func stubForecast(name string, temp float64, code int) *stubProvider {
+	p := &stubProvider{name: name}
+	p.weather.CurrentWeather.Temperature = temp
+	p.weather.CurrentWeather.WeatherCode = code
+	p.weather.Daily.Time = []string{"2024-05-01"}
+	p.weather.Daily.TemperatureMax = []float64{temp + 5}
+	p.weather.Daily.TemperatureMin = []float64{temp - 5}
+	p.weather.Daily.PrecipitationSum = []float64{temp / 10}
+	p.weather.Daily.WeatherCode = []int{code}
+	return p
+}
+
+func TestConsensusMedian(t *testing.T) {
+	c := &Consensus{Providers: []Provider{
+		stubForecast("a", 10, 61),
+		stubForecast("b", 14, 61),
+		stubForecast("c", 11, 3),
+		&stubProvider{name: "down", err: errors.New("unreachable")},
+	}}
+
+	weather, err := c.Fetch(context.Background(), 1, 2, Options{Daily: true})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if weather.CurrentWeather.Temperature != 11 {
+		t.Errorf("median temperature = %v; want 11", weather.CurrentWeather.Temperature)
+	}
+	if weather.CurrentWeather.WeatherCode != 61 {
+		t.Errorf("weather code = %d; want the majority 61", weather.CurrentWeather.WeatherCode)
+	}
+	if got := weather.Spread.Current.Temperature; got != (Range{Min: 10, Max: 14}) {
+		t.Errorf("spread = %+v; want 10–14", got)
+	}
+	if got := weather.Spread.Daily.TemperatureMax[0]; got != (Range{Min: 15, Max: 19}) {
+		t.Errorf("daily max spread = %+v; want 15–19", got)
+	}
+	if len(weather.Spread.Providers) != 3 {
+		t.Errorf("providers = %v; want the 3 that answered", weather.Spread.Providers)
+	}
+}
+
+func TestConsensusMean(t *testing.T) {
+	c := &Consensus{Method: ConsensusMean, Providers: []Provider{
+		stubForecast("a", 10, 0),
+		stubForecast("b", 14, 0),
+		stubForecast("c", 12, 0),
+	}}
+
+	weather, err := c.Fetch(context.Background(), 1, 2, Options{Daily: true})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if weather.Daily.TemperatureMin[0] != 7 {
+		t.Errorf("mean daily min = %v; want 7", weather.Daily.TemperatureMin[0])
+	}
+}
+
+func TestConsensusSkipsMissing(t *testing.T) {
+	// NWS reports no precipitation amounts
+	nws := stubForecast("nws", 12, 3)
+	nws.weather.Daily.PrecipitationSum = Values{math.NaN()}
+	c := &Consensus{Providers: []Provider{stubForecast("a", 10, 3), stubForecast("b", 30, 3), nws}}
+
+	weather, err := c.Fetch(context.Background(), 1, 2, Options{Daily: true})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got := weather.Daily.PrecipitationSum[0]; got != 2 {
+		t.Errorf("median precipitation = %v; want 2 from the providers that report it", got)
+	}
+	if got := weather.Spread.Daily.PrecipitationSum[0]; got != (Range{Min: 1, Max: 3}) {
+		t.Errorf("precipitation spread = %+v; want 1–3", got)
+	}
+
+	// With no amounts at all the blend stays missing, through a JSON round trip
+	c.Providers = []Provider{nws}
+	weather, _ = c.Fetch(context.Background(), 1, 2, Options{Daily: true})
+	data, err := json.Marshal(weather)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded WeatherData
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(decoded.Daily.PrecipitationSum[0]) || !decoded.Spread.Daily.PrecipitationSum[0].Missing() {
+		t.Errorf("decoded %s; want precipitation still missing", data)
+	}
+}
+
+func TestConsensusShortSeries(t *testing.T) {
+	// A provider whose value series stop short of its times must not bring
+	// the blend down
+	short := stubForecast("short", 30, 61)
+	short.weather.Daily.Time = append(short.weather.Daily.Time, "2024-05-02")
+	short.weather.Hourly.Time = []string{"2024-05-01T10:00"}
+	c := &Consensus{Providers: []Provider{stubForecast("a", 10, 3), short}}
+
+	weather, err := c.Fetch(context.Background(), 1, 2, Options{Daily: true, Hourly: true})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(weather.Daily.Time) != 1 || weather.Daily.TemperatureMax[0] != 25 {
+		t.Errorf("daily = %+v; want only the day with values", weather.Daily)
+	}
+	if len(weather.Hourly.Time) != 0 {
+		t.Errorf("hourly = %+v; want no hours without values", weather.Hourly)
+	}
+	if _, err := json.Marshal(weather); err != nil {
+		t.Errorf("blend does not encode: %v", err)
+	}
+}
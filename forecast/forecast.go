@@ -0,0 +1,143 @@
+// Package forecast retrieves weather forecasts from pluggable providers and
+// maps them into a provider-neutral model.
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WeatherData is the provider-neutral forecast model. Weather codes use the
+// WMO vocabulary. Hourly and current times are UTC, formatted as
+// "2006-01-02T15:04"; daily times are calendar dates ("2006-01-02") in
+// Options.Timezone.
+// Precipitation a provider does not report is NaN.
+type WeatherData struct {
+	Source         string `json:"source,omitempty"` // provider that produced the data
+	CurrentWeather struct {
+		Temperature float64 `json:"temperature"`
+		WindSpeed   float64 `json:"windspeed"`
+		WeatherCode int     `json:"weathercode"`
+		Time        string  `json:"time"`
+	} `json:"current_weather"`
+	Daily struct {
+		Time             []string  `json:"time"`
+		WeatherCode      []int     `json:"weathercode"`
+		TemperatureMax   []float64 `json:"temperature_2m_max"`
+		TemperatureMin   []float64 `json:"temperature_2m_min"`
+		PrecipitationSum Values    `json:"precipitation_sum"`
+	} `json:"daily"`
+	Hourly struct {
+		Time          []string  `json:"time"`
+		Temperature   []float64 `json:"temperature_2m"`
+		Precipitation Values    `json:"precipitation"`
+		WeatherCode   []int     `json:"weathercode"`
+	} `json:"hourly"`
+	Spread *Spread `json:"spread,omitempty"` // set for consensus forecasts
+}
+
+// Values is a series in which NaN marks a missing value. It encodes NaN as
+// JSON null, and decodes null back to NaN.
+type Values []float64
+
+// MarshalJSON implements json.Marshaler
+func (v Values) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	values := make([]*float64, len(v))
+	for i := range v {
+		if !math.IsNaN(v[i]) {
+			values[i] = &v[i]
+		}
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var values []*float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if values == nil {
+		*v = nil
+		return nil
+	}
+	*v = make(Values, len(values))
+	for i, value := range values {
+		(*v)[i] = math.NaN()
+		if value != nil {
+			(*v)[i] = *value
+		}
+	}
+	return nil
+}
+
+// Options selects which forecast series to request and in which units
+type Options struct {
+	Daily  bool
+	Hourly bool
+	Units  UnitSystem
+
+	// Timezone is the location's IANA time zone. Daily series cover its
+	// calendar days, or UTC days when it is empty or unknown.
+	Timezone string
+}
+
+// zone returns the time zone that daily series are dated in
+func (o Options) zone() *time.Location {
+	if o.Timezone == "" {
+		return time.UTC
+	}
+	zone, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return zone
+}
+
+// Provider fetches forecasts from a weather service
+type Provider interface {
+	// Name returns the identifier used in config and on the command line
+	Name() string
+	// Fetch downloads the forecast for the given coordinates
+	Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error)
+}
+
+// providers maps provider names to constructors
+var providers = map[string]func() Provider{
+	"open-meteo": func() Provider { return NewOpenMeteo() },
+	"nws":        func() Provider { return NewNWS() },
+	"met-norway": func() Provider { return NewMETNorway() },
+}
+
+// DefaultProvider is used when no provider is configured
+const DefaultProvider = "open-meteo"
+
+// NewProvider returns the provider registered under name
+func NewProvider(name string) (Provider, error) {
+	if name == "" {
+		name = DefaultProvider
+	}
+	newProvider, ok := providers[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return newProvider(), nil
+}
+
+// ProviderNames lists the registered provider names in sorted order
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
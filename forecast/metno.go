@@ -0,0 +1,281 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/cache"
+)
+
+// DefaultMETNorwayURL is the MET Norway locationforecast compact endpoint
+const DefaultMETNorwayURL = "https://api.met.no/weatherapi/locationforecast/2.0/compact"
+
+// METNorway retrieves forecasts from the Norwegian Meteorological Institute.
+// Its terms of service require an identifying User-Agent, which HTTP sends,
+// and that clients do not request data again before the Expires header and
+// revalidate with If-Modified-Since; responses are kept in Store for that
+// purpose.
+type METNorway struct {
+	BaseURL string
+	HTTP    *api.Client
+	Store   *cache.Cache // nil disables conditional requests
+}
+
+// NewMETNorway returns a METNorway provider using the public endpoint
+func NewMETNorway() *METNorway {
+	return &METNorway{
+		BaseURL: DefaultMETNorwayURL,
+		HTTP:    api.DefaultClient,
+		Store:   cache.New(METNorwayCacheDir(), 24*time.Hour),
+	}
+}
+
+// METNorwayCacheDir returns the default directory for stored MET Norway
+// responses
+func METNorwayCacheDir() string {
+	return filepath.Join(cache.DefaultDir(), "met-norway")
+}
+
+// Name implements Provider
+func (p *METNorway) Name() string {
+	return "met-norway"
+}
+
+// metResponse is the subset of the compact format we need
+type metResponse struct {
+	Properties struct {
+		Timeseries []struct {
+			Time string `json:"time"`
+			Data struct {
+				Instant struct {
+					Details struct {
+						AirTemperature float64 `json:"air_temperature"`
+						WindSpeed      float64 `json:"wind_speed"`
+					} `json:"details"`
+				} `json:"instant"`
+				Next1Hours *metPeriod `json:"next_1_hours"`
+				Next6Hours *metPeriod `json:"next_6_hours"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"properties"`
+}
+
+type metPeriod struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details struct {
+		PrecipitationAmount float64 `json:"precipitation_amount"`
+	} `json:"details"`
+}
+
+// metStored is a response kept for revalidation
+type metStored struct {
+	LastModified string    `json:"last_modified"`
+	Expires      time.Time `json:"expires"`
+	Body         []byte    `json:"body"`
+}
+
+// Fetch implements Provider. MET Norway always reports metric units and
+// UTC times; values are converted to the requested unit system and daily
+// aggregates follow the calendar days of opts.Timezone.
+func (p *METNorway) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	// MET asks for at most four decimals so responses can be shared
+	url := fmt.Sprintf("%s?lat=%.4f&lon=%.4f", p.BaseURL, lat, lon)
+
+	body, err := p.get(ctx, url)
+	if err != nil {
+		return WeatherData{}, err
+	}
+
+	var met metResponse
+	if err := json.Unmarshal(body, &met); err != nil {
+		return WeatherData{}, fmt.Errorf("could not parse weather data: %w", err)
+	}
+	series := met.Properties.Timeseries
+	if len(series) == 0 {
+		return WeatherData{}, fmt.Errorf("MET Norway returned no forecast")
+	}
+
+	var weather WeatherData
+	now := series[0]
+	weather.CurrentWeather.Temperature = convertTemp(now.Data.Instant.Details.AirTemperature, opts.Units)
+	weather.CurrentWeather.WindSpeed = convertWind(now.Data.Instant.Details.WindSpeed, opts.Units)
+	weather.CurrentWeather.WeatherCode = metWeatherCode(metSymbol(now.Data.Next1Hours, now.Data.Next6Hours))
+	weather.CurrentWeather.Time = metTime(now.Time, "2006-01-02T15:04")
+
+	if opts.Hourly {
+		for _, step := range series {
+			if len(weather.Hourly.Time) == 24 || step.Data.Next1Hours == nil {
+				break
+			}
+			weather.Hourly.Time = append(weather.Hourly.Time, metTime(step.Time, "2006-01-02T15:04"))
+			weather.Hourly.Temperature = append(weather.Hourly.Temperature, convertTemp(step.Data.Instant.Details.AirTemperature, opts.Units))
+			weather.Hourly.Precipitation = append(weather.Hourly.Precipitation, convertPrecip(step.Data.Next1Hours.Details.PrecipitationAmount, opts.Units))
+			weather.Hourly.WeatherCode = append(weather.Hourly.WeatherCode, metWeatherCode(step.Data.Next1Hours.Summary.SymbolCode))
+		}
+	}
+
+	if opts.Daily {
+		zone := opts.zone()
+		index := map[string]int{}
+		// Precipitation is summed over hourly steps and, where the series
+		// thins out, over the 6-hour steps, skipping hours already counted
+		var coveredUntil time.Time
+		for _, step := range series {
+			t, err := time.Parse(time.RFC3339, step.Time)
+			if err != nil {
+				continue
+			}
+			day := t.In(zone).Format("2006-01-02")
+			temp := convertTemp(step.Data.Instant.Details.AirTemperature, opts.Units)
+			code := metWeatherCode(metSymbol(step.Data.Next1Hours, step.Data.Next6Hours))
+
+			i, ok := index[day]
+			if !ok {
+				i = len(weather.Daily.Time)
+				index[day] = i
+				weather.Daily.Time = append(weather.Daily.Time, day)
+				weather.Daily.WeatherCode = append(weather.Daily.WeatherCode, code)
+				weather.Daily.TemperatureMax = append(weather.Daily.TemperatureMax, temp)
+				weather.Daily.TemperatureMin = append(weather.Daily.TemperatureMin, temp)
+				weather.Daily.PrecipitationSum = append(weather.Daily.PrecipitationSum, 0)
+			}
+
+			// Like Open-Meteo, the daily code is the most severe of the day
+			if code > weather.Daily.WeatherCode[i] {
+				weather.Daily.WeatherCode[i] = code
+			}
+			if temp > weather.Daily.TemperatureMax[i] {
+				weather.Daily.TemperatureMax[i] = temp
+			}
+			if temp < weather.Daily.TemperatureMin[i] {
+				weather.Daily.TemperatureMin[i] = temp
+			}
+
+			if t.Before(coveredUntil) {
+				continue
+			}
+			if step.Data.Next1Hours != nil {
+				weather.Daily.PrecipitationSum[i] += convertPrecip(step.Data.Next1Hours.Details.PrecipitationAmount, opts.Units)
+				coveredUntil = t.Add(time.Hour)
+			} else if step.Data.Next6Hours != nil {
+				weather.Daily.PrecipitationSum[i] += convertPrecip(step.Data.Next6Hours.Details.PrecipitationAmount, opts.Units)
+				coveredUntil = t.Add(6 * time.Hour)
+			}
+		}
+	}
+
+	return weather, nil
+}
+
+// get returns the response body for url, reusing the stored copy until it
+// expires and revalidating it with If-Modified-Since afterwards
+func (p *METNorway) get(ctx context.Context, url string) ([]byte, error) {
+	key := cache.Key(url)
+	var stored metStored
+	haveStored := p.Store != nil && p.Store.Get(key, &stored)
+	if haveStored && time.Now().Before(stored.Expires) {
+		return stored.Body, nil
+	}
+
+	header := http.Header{}
+	if haveStored && stored.LastModified != "" {
+		header.Set("If-Modified-Since", stored.LastModified)
+	}
+
+	resp, err := p.HTTP.Get(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+	body := resp.Body
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && haveStored:
+		body = stored.Body
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNonAuthoritativeInfo:
+		// 203 marks a deprecated API version but still carries data
+		stored.LastModified = resp.Header.Get("Last-Modified")
+	default:
+		if err := api.CheckResponse(resp.Response, body); err != nil {
+			return nil, fmt.Errorf("MET Norway request failed: %w", err)
+		}
+		return nil, fmt.Errorf("MET Norway request failed: unexpected %s", resp.Status)
+	}
+
+	if p.Store != nil {
+		stored.Body = body
+		stored.Expires, _ = http.ParseTime(resp.Header.Get("Expires"))
+		_ = p.Store.Put(key, stored)
+	}
+	return body, nil
+}
+
+// metSymbol returns the symbol code of the shortest period available
+func metSymbol(periods ...*metPeriod) string {
+	for _, period := range periods {
+		if period != nil {
+			return period.Summary.SymbolCode
+		}
+	}
+	return ""
+}
+
+// metTime reformats a MET timestamp, which is always UTC
+func metTime(s, layout string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(layout)
+}
+
+// metSymbolCodes maps MET Norway symbol codes, without their
+// _day/_night/_polartwilight suffix, to WMO weather codes. WMO has no code
+// for MET's sleet (a rain and snow mix), so it gets 77 as NWS sleet does,
+// not the freezing rain codes 66 and 67.
+var metSymbolCodes = map[string]int{
+	"clearsky":          0,
+	"fair":              1,
+	"partlycloudy":      2,
+	"cloudy":            3,
+	"fog":               45,
+	"lightrain":         61,
+	"rain":              63,
+	"heavyrain":         65,
+	"lightrainshowers":  80,
+	"rainshowers":       81,
+	"heavyrainshowers":  82,
+	"lightsleet":        77,
+	"sleet":             77,
+	"heavysleet":        77,
+	"lightsleetshowers": 85,
+	"sleetshowers":      85,
+	"heavysleetshowers": 86,
+	"lightsnow":         71,
+	"snow":              73,
+	"heavysnow":         75,
+	"lightsnowshowers":  85,
+	"snowshowers":       85,
+	"heavysnowshowers":  86,
+}
+
+// metWeatherCode converts a MET Norway symbol code to a WMO weather code
+func metWeatherCode(symbol string) int {
+	if i := strings.Index(symbol, "_"); i >= 0 {
+		symbol = symbol[:i]
+	}
+	if strings.Contains(symbol, "thunder") {
+		return 95
+	}
+	if code, ok := metSymbolCodes[symbol]; ok {
+		return code
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/cache"
+)
+
+const metCompact = `{"properties":{"timeseries":[
+	{"time":"2024-05-01T12:00:00Z","data":{"instant":{"details":{"air_temperature":10.0,"wind_speed":5.0}},
+		"next_1_hours":{"summary":{"symbol_code":"lightrainshowers_day"},"details":{"precipitation_amount":1.5}}}},
+	{"time":"2024-05-01T13:00:00Z","data":{"instant":{"details":{"air_temperature":12.0,"wind_speed":4.0}},
+		"next_1_hours":{"summary":{"symbol_code":"rainandthunder"},"details":{"precipitation_amount":2.0}}}},
+	{"time":"2024-05-02T00:00:00Z","data":{"instant":{"details":{"air_temperature":4.0,"wind_speed":1.0}},
+		"next_6_hours":{"summary":{"symbol_code":"clearsky_night"},"details":{"precipitation_amount":0.5}}}}
+]}}`
+
+func TestMETNorwayFetch(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request sent without User-Agent")
+		}
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		// Already expired, so the next fetch must revalidate
+		w.Header().Set("Expires", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 11:30:00 GMT")
+		fmt.Fprint(w, metCompact)
+	}))
+	defer server.Close()
+
+	p := NewMETNorway()
+	p.BaseURL = server.URL
+	p.Store = cache.New(t.TempDir(), time.Hour)
+
+	weather, err := p.Fetch(context.Background(), 59.9139, 10.7522, Options{Daily: true, Hourly: true, Units: UnitMetric})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if weather.CurrentWeather.WeatherCode != 80 || weather.CurrentWeather.WindSpeed != 18 {
+		t.Errorf("current = %+v; want code 80 and 18 km/h", weather.CurrentWeather)
+	}
+	if len(weather.Hourly.Time) != 2 || weather.Hourly.WeatherCode[1] != 95 {
+		t.Errorf("hourly = %+v; want 2 hours ending in a thunderstorm", weather.Hourly)
+	}
+	if len(weather.Daily.Time) != 2 || weather.Daily.PrecipitationSum[0] != 3.5 || weather.Daily.TemperatureMax[0] != 12 {
+		t.Errorf("daily = %+v; want 2 days, first with 3.5mm and max 12", weather.Daily)
+	}
+
+	// Revalidates with If-Modified-Since, then honors the new Expires
+	for i := 0; i < 2; i++ {
+		if _, err := p.Fetch(context.Background(), 59.9139, 10.7522, Options{Units: UnitMetric}); err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests; want 2", requests)
+	}
+}
+
+func TestMETWeatherCode(t *testing.T) {
+	tests := map[string]int{
+		"clearsky_day":                    0,
+		"partlycloudy_polartwilight":      2,
+		"heavysnow":                       75,
+		"lightsleet":                      77,
+		"heavysleet_night":                77,
+		"lightsleetshowersandthunder_day": 95,
+		"unknown":                         -1,
+	}
+	for symbol, want := range tests {
+		if got := metWeatherCode(symbol); got != want {
+			t.Errorf("metWeatherCode(%q) = %d; want %d", symbol, got, want)
+		}
+	}
+}
+
+func TestMETNorwayLocalDays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, metCompact)
+	}))
+	defer server.Close()
+
+	p := NewMETNorway()
+	p.BaseURL = server.URL
+	p.Store = nil
+
+	// Midnight UTC is still the evening of May 1 in New York
+	weather, err := p.Fetch(context.Background(), 40.71, -74.01, Options{Daily: true, Timezone: "America/New_York"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(weather.Daily.Time) != 1 || weather.Daily.Time[0] != "2024-05-01" || weather.Daily.TemperatureMin[0] != 4 {
+		t.Errorf("daily = %+v; want one local day including the 4° evening", weather.Daily)
+	}
+}
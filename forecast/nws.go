@@ -0,0 +1,251 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/api"
+)
+
+// DefaultNWSURL is the National Weather Service API endpoint
+const DefaultNWSURL = "https://api.weather.gov"
+
+// NWS retrieves forecasts from the US National Weather Service
+// (api.weather.gov). Coverage is limited to the United States and its
+// territories. The gridpoint forecasts carry no precipitation amounts, so
+// precipitation series are reported as missing (NaN).
+type NWS struct {
+	BaseURL string
+	HTTP    *api.Client
+}
+
+// NewNWS returns an NWS provider using the public endpoint
+func NewNWS() *NWS {
+	return &NWS{
+		BaseURL: DefaultNWSURL,
+		HTTP:    api.DefaultClient,
+	}
+}
+
+// Name implements Provider
+func (p *NWS) Name() string {
+	return "nws"
+}
+
+// nwsPoint is the subset of the /points response we need
+type nwsPoint struct {
+	Properties struct {
+		Forecast       string `json:"forecast"`
+		ForecastHourly string `json:"forecastHourly"`
+	} `json:"properties"`
+}
+
+// nwsForecast is a gridpoints forecast or hourly forecast response
+type nwsForecast struct {
+	Properties struct {
+		Periods []nwsPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+type nwsPeriod struct {
+	StartTime     string  `json:"startTime"`
+	IsDaytime     bool    `json:"isDaytime"`
+	Temperature   float64 `json:"temperature"`
+	WindSpeed     string  `json:"windSpeed"`
+	Icon          string  `json:"icon"`
+	ShortForecast string  `json:"shortForecast"`
+}
+
+// Fetch implements Provider. It resolves the forecast grid via /points and
+// then downloads the hourly and, if requested, the 12-hour period forecast.
+func (p *NWS) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	var point nwsPoint
+	if err := p.get(ctx, fmt.Sprintf("%s/points/%.4f,%.4f", p.BaseURL, lat, lon), opts, &point); err != nil {
+		return WeatherData{}, err
+	}
+
+	var weather WeatherData
+
+	// The first hourly period doubles as current conditions
+	var hourly nwsForecast
+	if err := p.get(ctx, point.Properties.ForecastHourly, opts, &hourly); err != nil {
+		return WeatherData{}, err
+	}
+	periods := hourly.Properties.Periods
+	if len(periods) == 0 {
+		return WeatherData{}, fmt.Errorf("NWS returned no hourly forecast periods")
+	}
+	weather.CurrentWeather.Temperature = periods[0].Temperature
+	weather.CurrentWeather.WindSpeed = parseNWSWind(periods[0].WindSpeed)
+	weather.CurrentWeather.WeatherCode = nwsWeatherCode(periods[0].Icon)
+	weather.CurrentWeather.Time = nwsTime(periods[0].StartTime, "2006-01-02T15:04", time.UTC)
+
+	if opts.Hourly {
+		for i := 0; i < 24 && i < len(periods); i++ {
+			weather.Hourly.Time = append(weather.Hourly.Time, nwsTime(periods[i].StartTime, "2006-01-02T15:04", time.UTC))
+			weather.Hourly.Temperature = append(weather.Hourly.Temperature, periods[i].Temperature)
+			weather.Hourly.Precipitation = append(weather.Hourly.Precipitation, math.NaN())
+			weather.Hourly.WeatherCode = append(weather.Hourly.WeatherCode, nwsWeatherCode(periods[i].Icon))
+		}
+	}
+
+	if opts.Daily {
+		var daily nwsForecast
+		if err := p.get(ctx, point.Properties.Forecast, opts, &daily); err != nil {
+			return WeatherData{}, err
+		}
+		mergeNWSDaily(&weather, daily.Properties.Periods, opts.zone())
+	}
+
+	return weather, nil
+}
+
+// mergeNWSDaily folds day/night periods into one entry per date in zone,
+// using the daytime temperature as the high, the overnight one as the low and
+// the daytime condition where available
+func mergeNWSDaily(weather *WeatherData, periods []nwsPeriod, zone *time.Location) {
+	index := map[string]int{}
+	for _, period := range periods {
+		day := nwsTime(period.StartTime, "2006-01-02", zone)
+		i, ok := index[day]
+		if !ok {
+			i = len(weather.Daily.Time)
+			index[day] = i
+			weather.Daily.Time = append(weather.Daily.Time, day)
+			weather.Daily.WeatherCode = append(weather.Daily.WeatherCode, nwsWeatherCode(period.Icon))
+			weather.Daily.TemperatureMax = append(weather.Daily.TemperatureMax, period.Temperature)
+			weather.Daily.TemperatureMin = append(weather.Daily.TemperatureMin, period.Temperature)
+			weather.Daily.PrecipitationSum = append(weather.Daily.PrecipitationSum, math.NaN())
+			continue
+		}
+		if period.IsDaytime {
+			weather.Daily.WeatherCode[i] = nwsWeatherCode(period.Icon)
+		}
+		if period.Temperature > weather.Daily.TemperatureMax[i] {
+			weather.Daily.TemperatureMax[i] = period.Temperature
+		}
+		if period.Temperature < weather.Daily.TemperatureMin[i] {
+			weather.Daily.TemperatureMin[i] = period.Temperature
+		}
+	}
+}
+
+// get downloads and decodes an NWS JSON document
+func (p *NWS) get(ctx context.Context, url string, opts Options, v interface{}) error {
+	if url == "" {
+		return fmt.Errorf("NWS returned no forecast URL for this location")
+	}
+
+	// NWS defaults to US customary units; "si" selects °C and km/h
+	if opts.Units != UnitImperial {
+		if strings.Contains(url, "?") {
+			url += "&units=si"
+		} else {
+			url += "?units=si"
+		}
+	}
+
+	resp, err := p.HTTP.Get(ctx, url, http.Header{"Accept": {"application/geo+json"}})
+	if err != nil {
+		return err
+	}
+	if err := api.CheckResponse(resp.Response, resp.Body); err != nil {
+		return fmt.Errorf("NWS request failed: %w", err)
+	}
+
+	if err := json.Unmarshal(resp.Body, v); err != nil {
+		return fmt.Errorf("could not parse weather data: %w", err)
+	}
+	return nil
+}
+
+// nwsTime reformats an NWS timestamp in zone: UTC for hourly times, like the
+// other providers, and the location's zone for daily dates
+func nwsTime(s, layout string, zone *time.Location) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.In(zone).Format(layout)
+}
+
+var windSpeedPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// parseNWSWind extracts the highest speed from strings like "5 to 10 mph"
+func parseNWSWind(s string) float64 {
+	var speed float64
+	for _, match := range windSpeedPattern.FindAllString(s, -1) {
+		if v, err := strconv.ParseFloat(match, 64); err == nil && v > speed {
+			speed = v
+		}
+	}
+	return speed
+}
+
+// nwsIconCodes maps NWS icon condition names to WMO weather codes
+var nwsIconCodes = map[string]int{
+	"skc":             0,
+	"few":             1,
+	"sct":             2,
+	"bkn":             2,
+	"ovc":             3,
+	"wind_skc":        0,
+	"wind_few":        1,
+	"wind_sct":        2,
+	"wind_bkn":        2,
+	"wind_ovc":        3,
+	"snow":            73,
+	"rain_snow":       73,
+	"rain_sleet":      66,
+	"snow_sleet":      77,
+	"fzra":            67,
+	"rain_fzra":       66,
+	"snow_fzra":       67,
+	"sleet":           77,
+	"rain":            63,
+	"rain_showers":    80,
+	"rain_showers_hi": 80,
+	"tsra":            95,
+	"tsra_sct":        95,
+	"tsra_hi":         95,
+	"tornado":         99,
+	"hurricane":       99,
+	"tropical_storm":  95,
+	"dust":            45,
+	"smoke":           45,
+	"haze":            45,
+	"hot":             0,
+	"cold":            0,
+	"blizzard":        75,
+	"fog":             45,
+}
+
+// nwsWeatherCode converts an NWS icon URL such as
+// ".../icons/land/day/rain_showers,30/tsra,60?size=medium" to a WMO code,
+// using the first condition in the path
+func nwsWeatherCode(icon string) int {
+	if i := strings.Index(icon, "?"); i >= 0 {
+		icon = icon[:i]
+	}
+	parts := strings.Split(icon, "/")
+	for i, part := range parts {
+		if part != "day" && part != "night" || i+1 >= len(parts) {
+			continue
+		}
+		condition := parts[i+1]
+		if j := strings.Index(condition, ","); j >= 0 {
+			condition = condition[:j]
+		}
+		if code, ok := nwsIconCodes[condition]; ok {
+			return code
+		}
+	}
+	return -1
+}
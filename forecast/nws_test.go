@@ -0,0 +1,113 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newFakeNWS serves a minimal points, forecast and hourly forecast
+func newFakeNWS(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/points/40.7128,-74.0060", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request sent without User-Agent")
+		}
+		fmt.Fprintf(w, `{"properties":{"forecast":"%[1]s/gridpoints/OKX/33,35/forecast","forecastHourly":"%[1]s/gridpoints/OKX/33,35/forecast/hourly"}}`, server.URL)
+	})
+	mux.HandleFunc("/gridpoints/OKX/33,35/forecast/hourly", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("units"); got != "si" {
+			t.Errorf("units = %q; want si", got)
+		}
+		fmt.Fprint(w, `{"properties":{"periods":[
+			{"startTime":"2024-05-01T14:00:00-04:00","isDaytime":true,"temperature":21,"windSpeed":"10 km/h","icon":"https://api.weather.gov/icons/land/day/sct?size=small"},
+			{"startTime":"2024-05-01T15:00:00-04:00","isDaytime":true,"temperature":22,"windSpeed":"5 to 15 km/h","icon":"https://api.weather.gov/icons/land/day/tsra,40?size=small"}
+		]}}`)
+	})
+	mux.HandleFunc("/gridpoints/OKX/33,35/forecast", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"properties":{"periods":[
+			{"startTime":"2024-05-01T06:00:00-04:00","isDaytime":true,"temperature":24,"icon":"https://api.weather.gov/icons/land/day/rain_showers,30?size=medium"},
+			{"startTime":"2024-05-01T18:00:00-04:00","isDaytime":false,"temperature":12,"icon":"https://api.weather.gov/icons/land/night/skc?size=medium"},
+			{"startTime":"2024-05-02T06:00:00-04:00","isDaytime":true,"temperature":26,"icon":"https://api.weather.gov/icons/land/day/ovc?size=medium"}
+		]}}`)
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestNWSFetch(t *testing.T) {
+	server := newFakeNWS(t)
+	defer server.Close()
+
+	p := NewNWS()
+	p.BaseURL = server.URL
+	weather, err := p.Fetch(context.Background(), 40.7128, -74.0060, Options{Daily: true, Hourly: true, Units: UnitMetric})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if weather.CurrentWeather.Temperature != 21 || weather.CurrentWeather.WeatherCode != 2 {
+		t.Errorf("current = %+v; want 21° and code 2", weather.CurrentWeather)
+	}
+	if weather.CurrentWeather.Time != "2024-05-01T18:00" {
+		t.Errorf("current time = %q; want UTC 2024-05-01T18:00", weather.CurrentWeather.Time)
+	}
+	if len(weather.Hourly.Time) != 2 || weather.Hourly.WeatherCode[1] != 95 {
+		t.Errorf("hourly = %+v; want 2 periods ending in a thunderstorm", weather.Hourly)
+	}
+
+	if len(weather.Daily.Time) != 2 {
+		t.Fatalf("daily has %d days; want 2", len(weather.Daily.Time))
+	}
+	if !math.IsNaN(weather.Hourly.Precipitation[0]) || !math.IsNaN(weather.Daily.PrecipitationSum[0]) {
+		t.Errorf("precipitation = %v, %v; want missing", weather.Hourly.Precipitation, weather.Daily.PrecipitationSum)
+	}
+	if weather.Daily.TemperatureMax[0] != 24 || weather.Daily.TemperatureMin[0] != 12 || weather.Daily.WeatherCode[0] != 80 {
+		t.Errorf("day 1 = max %v min %v code %v; want 24/12/80",
+			weather.Daily.TemperatureMax[0], weather.Daily.TemperatureMin[0], weather.Daily.WeatherCode[0])
+	}
+}
+
+func TestNWSLocalDays(t *testing.T) {
+	periods := []nwsPeriod{
+		{StartTime: "2024-05-01T06:00:00-07:00", IsDaytime: true, Temperature: 24},
+		{StartTime: "2024-05-01T18:00:00-07:00", Temperature: 12},
+	}
+
+	// 18:00 in Los Angeles is already May 2 in UTC
+	var local, utc WeatherData
+	mergeNWSDaily(&local, periods, mustZone(t, "America/Los_Angeles"))
+	mergeNWSDaily(&utc, periods, time.UTC)
+	if len(local.Daily.Time) != 1 || local.Daily.TemperatureMin[0] != 12 {
+		t.Errorf("local days = %+v; want one day with the overnight low", local.Daily)
+	}
+	if len(utc.Daily.Time) != 2 || utc.Daily.Time[1] != "2024-05-02" {
+		t.Errorf("UTC days = %v; want the night on May 2", utc.Daily.Time)
+	}
+}
+
+func mustZone(t *testing.T, name string) *time.Location {
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	return zone
+}
+
+func TestParseNWSWind(t *testing.T) {
+	tests := map[string]float64{
+		"10 mph":       10,
+		"5 to 15 km/h": 15,
+		"":             0,
+	}
+	for in, want := range tests {
+		if got := parseNWSWind(in); got != want {
+			t.Errorf("parseNWSWind(%q) = %v; want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/streek/go-weather/api"
+)
+
+// DefaultOpenMeteoURL is the Open-Meteo forecast endpoint
+const DefaultOpenMeteoURL = "https://api.open-meteo.com/v1/forecast"
+
+// OpenMeteo retrieves forecasts from Open-Meteo
+type OpenMeteo struct {
+	BaseURL string
+	HTTP    *api.Client
+}
+
+// NewOpenMeteo returns an OpenMeteo provider using the public endpoint
+func NewOpenMeteo() *OpenMeteo {
+	return &OpenMeteo{
+		BaseURL: DefaultOpenMeteoURL,
+		HTTP:    api.DefaultClient,
+	}
+}
+
+// Name implements Provider
+func (p *OpenMeteo) Name() string {
+	return "open-meteo"
+}
+
+// openMeteoResponse is the subset of the forecast response we need, with
+// times as unix seconds
+type openMeteoResponse struct {
+	CurrentWeather struct {
+		Temperature float64 `json:"temperature"`
+		WindSpeed   float64 `json:"windspeed"`
+		WeatherCode int     `json:"weathercode"`
+		Time        int64   `json:"time"`
+	} `json:"current_weather"`
+	Daily struct {
+		Time             []int64   `json:"time"`
+		WeatherCode      []int     `json:"weathercode"`
+		TemperatureMax   []float64 `json:"temperature_2m_max"`
+		TemperatureMin   []float64 `json:"temperature_2m_min"`
+		PrecipitationSum Values    `json:"precipitation_sum"`
+	} `json:"daily"`
+	Hourly struct {
+		Time          []int64   `json:"time"`
+		Temperature   []float64 `json:"temperature_2m"`
+		Precipitation Values    `json:"precipitation"`
+		WeatherCode   []int     `json:"weathercode"`
+	} `json:"hourly"`
+}
+
+// Fetch implements Provider. Daily aggregates are requested for the
+// calendar days of opts.Timezone; times come back as unix seconds, which
+// keeps hourly times unambiguous across daylight saving changes.
+func (p *OpenMeteo) Fetch(ctx context.Context, lat, lon float64, opts Options) (WeatherData, error) {
+	// Build URL with parameters for requested forecast types
+	endpoint := fmt.Sprintf("%s?latitude=%f&longitude=%f&current_weather=true&timeformat=unixtime", p.BaseURL, lat, lon)
+
+	// Add unit-specific parameters
+	if opts.Units == UnitImperial {
+		endpoint += "&temperature_unit=fahrenheit&windspeed_unit=mph&precipitation_unit=inch"
+	}
+
+	zone := opts.zone()
+	if opts.Daily {
+		endpoint += "&daily=weathercode,temperature_2m_max,temperature_2m_min,precipitation_sum"
+		if zone != time.UTC {
+			endpoint += "&timezone=" + url.QueryEscape(zone.String())
+		}
+	}
+
+	if opts.Hourly {
+		endpoint += "&hourly=temperature_2m,precipitation,weathercode&forecast_hours=24"
+	}
+
+	resp, err := p.HTTP.Get(ctx, endpoint, nil)
+	if err != nil {
+		return WeatherData{}, err
+	}
+	if err := api.CheckResponse(resp.Response, resp.Body); err != nil {
+		return WeatherData{}, fmt.Errorf("API request failed: %w", err)
+	}
+
+	var raw openMeteoResponse
+	if err := json.Unmarshal(resp.Body, &raw); err != nil {
+		return WeatherData{}, fmt.Errorf("could not parse weather data: %w", err)
+	}
+
+	var weather WeatherData
+	weather.CurrentWeather.Temperature = raw.CurrentWeather.Temperature
+	weather.CurrentWeather.WindSpeed = raw.CurrentWeather.WindSpeed
+	weather.CurrentWeather.WeatherCode = raw.CurrentWeather.WeatherCode
+	weather.CurrentWeather.Time = unixTime(raw.CurrentWeather.Time, time.UTC, "2006-01-02T15:04")
+
+	// Daily times are the instants of local midnight
+	for _, t := range raw.Daily.Time {
+		weather.Daily.Time = append(weather.Daily.Time, unixTime(t, zone, "2006-01-02"))
+	}
+	weather.Daily.WeatherCode = raw.Daily.WeatherCode
+	weather.Daily.TemperatureMax = raw.Daily.TemperatureMax
+	weather.Daily.TemperatureMin = raw.Daily.TemperatureMin
+	weather.Daily.PrecipitationSum = raw.Daily.PrecipitationSum
+
+	for _, t := range raw.Hourly.Time {
+		weather.Hourly.Time = append(weather.Hourly.Time, unixTime(t, time.UTC, "2006-01-02T15:04"))
+	}
+	weather.Hourly.Temperature = raw.Hourly.Temperature
+	weather.Hourly.Precipitation = raw.Hourly.Precipitation
+	weather.Hourly.WeatherCode = raw.Hourly.WeatherCode
+	return weather, nil
+}
+
+// unixTime formats unix seconds in zone
+func unixTime(sec int64, zone *time.Location, layout string) string {
+	return time.Unix(sec, 0).In(zone).Format(layout)
+}
@@ -0,0 +1,46 @@
+package forecast
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenMeteoFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("timezone") != "America/New_York" || q.Get("timeformat") != "unixtime" {
+			t.Errorf("query = %v; want the location's time zone and unix times", q)
+		}
+		// Daily times are local midnights: 2024-05-01T04:00Z is midnight EDT
+		w.Write([]byte(`{
+			"current_weather": {"temperature": 18.5, "windspeed": 12, "weathercode": 3, "time": 1714564800},
+			"daily": {"time": [1714536000, 1714622400], "weathercode": [61, 3],
+				"temperature_2m_max": [19, 17], "temperature_2m_min": [9, 8], "precipitation_sum": [2.5, null]},
+			"hourly": {"time": [1714564800, 1714568400], "temperature_2m": [18.5, 19],
+				"precipitation": [0, 0.2], "weathercode": [3, 61]}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenMeteo()
+	p.BaseURL = server.URL
+	weather, err := p.Fetch(context.Background(), 40.71, -74.01, Options{Daily: true, Hourly: true, Timezone: "America/New_York"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if weather.CurrentWeather.Time != "2024-05-01T12:00" || weather.CurrentWeather.Temperature != 18.5 {
+		t.Errorf("current = %+v; want 18.5° at 12:00 UTC", weather.CurrentWeather)
+	}
+	if len(weather.Daily.Time) != 2 || weather.Daily.Time[0] != "2024-05-01" || weather.Daily.Time[1] != "2024-05-02" {
+		t.Errorf("daily times = %v; want the local dates", weather.Daily.Time)
+	}
+	if !math.IsNaN(weather.Daily.PrecipitationSum[1]) {
+		t.Errorf("precipitation = %v; want null decoded as missing", weather.Daily.PrecipitationSum)
+	}
+	if len(weather.Hourly.Time) != 2 || weather.Hourly.Time[1] != "2024-05-01T13:00" {
+		t.Errorf("hourly times = %v; want UTC", weather.Hourly.Time)
+	}
+}
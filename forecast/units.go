@@ -0,0 +1,70 @@
+package forecast
+
+// UnitSystem represents measurement units to use
+type UnitSystem string
+
+// Available unit systems
+const (
+	UnitMetric   UnitSystem = "metric"
+	UnitImperial UnitSystem = "imperial"
+)
+
+// TempUnit returns the temperature unit for the unit system
+func TempUnit(unitSystem UnitSystem) string {
+	if unitSystem == UnitImperial {
+		return "°F"
+	}
+	return "°C"
+}
+
+// WindUnit returns the wind speed unit for the unit system
+func WindUnit(unitSystem UnitSystem) string {
+	if unitSystem == UnitImperial {
+		return "mph"
+	}
+	return "km/h"
+}
+
+// PrecipUnit returns the precipitation unit for the unit system
+func PrecipUnit(unitSystem UnitSystem) string {
+	if unitSystem == UnitImperial {
+		return "in"
+	}
+	return "mm"
+}
+
+// UnitSystemName returns a human-readable name for the unit system
+func UnitSystemName(unit UnitSystem) string {
+	switch unit {
+	case UnitMetric:
+		return "Metric (°C, km/h, mm)"
+	case UnitImperial:
+		return "Imperial (°F, mph, in)"
+	default:
+		return string(unit)
+	}
+}
+
+// convertTemp converts a Celsius value to the unit system
+func convertTemp(celsius float64, unitSystem UnitSystem) float64 {
+	if unitSystem == UnitImperial {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// convertWind converts a speed in m/s to the unit system
+func convertWind(metersPerSecond float64, unitSystem UnitSystem) float64 {
+	if unitSystem == UnitImperial {
+		return metersPerSecond * 2.236936
+	}
+	return metersPerSecond * 3.6
+}
+
+// convertPrecip converts millimeters to the unit system
+func convertPrecip(mm float64, unitSystem UnitSystem) float64 {
+	if unitSystem == UnitImperial {
+		return mm / 25.4
+	}
+	return mm
+}
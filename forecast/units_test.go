@@ -0,0 +1,26 @@
+package forecast
+
+import (
+	"testing"
+)
+
+func TestTempUnit(t *testing.T) {
+	tests := []struct {
+		name       string
+		unitSystem UnitSystem
+		want       string
+	}{
+		{"metric", UnitMetric, "°C"},
+		{"imperial", UnitImperial, "°F"},
+		{"empty", "", "°C"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := TempUnit(tc.unitSystem)
+			if got != tc.want {
+				t.Errorf("TempUnit(%s) = %s; want %s", tc.unitSystem, got, tc.want)
+			}
+		})
+	}
+}
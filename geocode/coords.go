@@ -0,0 +1,94 @@
+package geocode
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseCoordinates decodes locations that need no geocoding request: a
+// "lat,lon" pair, an RFC 5870 "geo:" URI or a full Open Location Code (plus
+// code). ok is false when s is none of these; err reports a recognized but
+// invalid location, such as an out-of-range latitude.
+func ParseCoordinates(s string) (loc GeoLocation, ok bool, err error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case len(s) > 4 && strings.EqualFold(s[:4], "geo:"):
+		lat, lon, err := parseGeoURI(s)
+		if err != nil {
+			return GeoLocation{}, true, err
+		}
+		return coordinateLocation(lat, lon, ""), true, nil
+
+	case IsFullPlusCode(s):
+		lat, lon, err := DecodePlusCode(s)
+		if err != nil {
+			return GeoLocation{}, true, err
+		}
+		return coordinateLocation(lat, lon, strings.ToUpper(s)), true, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return GeoLocation{}, false, nil
+	}
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if latErr != nil || lonErr != nil {
+		return GeoLocation{}, false, nil
+	}
+	if err := checkRange(lat, lon); err != nil {
+		return GeoLocation{}, true, err
+	}
+	return coordinateLocation(lat, lon, ""), true, nil
+}
+
+// parseGeoURI decodes "geo:lat,lon[,alt][;crs=wgs84][;u=...]"
+func parseGeoURI(uri string) (float64, float64, error) {
+	params := strings.Split(uri[4:], ";")
+	for _, param := range params[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if strings.EqualFold(kv[0], "crs") && len(kv) == 2 && !strings.EqualFold(kv[1], "wgs84") {
+			return 0, 0, fmt.Errorf("unsupported geo URI coordinate system %q", kv[1])
+		}
+	}
+
+	coords := strings.Split(params[0], ",")
+	if len(coords) < 2 || len(coords) > 3 {
+		return 0, 0, fmt.Errorf("invalid geo URI %q", uri)
+	}
+	lat, err := strconv.ParseFloat(coords[0], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid geo URI latitude %q", coords[0])
+	}
+	lon, err := strconv.ParseFloat(coords[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid geo URI longitude %q", coords[1])
+	}
+	return lat, lon, checkRange(lat, lon)
+}
+
+// checkRange rejects coordinates off the globe, including NaN and infinities,
+// which ParseFloat accepts
+func checkRange(lat, lon float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lon) || math.IsInf(lat, 0) || math.IsInf(lon, 0) {
+		return fmt.Errorf("coordinates %g, %g are not finite numbers", lat, lon)
+	}
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude %g out of range -90 to 90", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude %g out of range -180 to 180", lon)
+	}
+	return nil
+}
+
+// coordinateLocation names a location after its coordinates, or label if set
+func coordinateLocation(lat, lon float64, label string) GeoLocation {
+	if label == "" {
+		label = fmt.Sprintf("%.4f, %.4f", lat, lon)
+	}
+	return GeoLocation{Latitude: lat, Longitude: lon, Name: label}
+}
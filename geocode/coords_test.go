@@ -0,0 +1,65 @@
+package geocode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		in       string
+		ok       bool
+		wantErr  bool
+		lat, lon float64
+	}{
+		{"40.7128,-74.0060", true, false, 40.7128, -74.006},
+		{" 51.5074 , -0.1278 ", true, false, 51.5074, -0.1278},
+		{"geo:37.786971,-122.399677", true, false, 37.786971, -122.399677},
+		{"GEO:48.2010,16.3695,183;crs=wgs84;u=40", true, false, 48.201, 16.3695},
+		{"geo:48.2,16.3;crs=nad27", true, true, 0, 0},
+		{"91,0", true, true, 0, 0},
+		{"NaN,0", true, true, 0, 0},
+		{"0,nan", true, true, 0, 0},
+		{"Inf,0", true, true, 0, 0},
+		{"0,-Infinity", true, true, 0, 0},
+		{"geo:NaN,NaN", true, true, 0, 0},
+		{"849VCWC8+R9", true, false, 37.4220625, -122.0840625},
+		{"8FVC0000+", true, false, 47.5, 8.5},
+		{"Paris, France", false, false, 0, 0},
+		{"10001", false, false, 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			loc, ok, err := ParseCoordinates(tc.in)
+			if ok != tc.ok || (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCoordinates = ok %v, err %v; want ok %v, error %v", ok, err, tc.ok, tc.wantErr)
+			}
+			if !ok || err != nil {
+				return
+			}
+			if math.Abs(loc.Latitude-tc.lat) > 1e-6 || math.Abs(loc.Longitude-tc.lon) > 1e-6 {
+				t.Errorf("got %v, %v; want %v, %v", loc.Latitude, loc.Longitude, tc.lat, tc.lon)
+			}
+		})
+	}
+}
+
+func TestRecoverPlusCode(t *testing.T) {
+	tests := []struct {
+		short          string
+		refLat, refLon float64
+		want           string
+	}{
+		{"9G8F+6W", 47.365590, 8.524997, "8FVC9G8F+6W"},
+		{"CWC8+R9", 37.4, -122.1, "849VCWC8+R9"},
+		// Reference across a cell boundary from the code
+		{"2222+22", 47.0000625, 8.0000625, "8FVC2222+22"},
+	}
+	for _, tc := range tests {
+		got, err := RecoverPlusCode(tc.short, tc.refLat, tc.refLon)
+		if err != nil || got != tc.want {
+			t.Errorf("RecoverPlusCode(%q) = %q, %v; want %q", tc.short, got, err, tc.want)
+		}
+	}
+}
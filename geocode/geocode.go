@@ -0,0 +1,292 @@
+// Package geocode converts location names and postal codes into coordinates
+// using the Open-Meteo geocoding API.
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/api"
+)
+
+// DefaultBaseURL is the Open-Meteo geocoding endpoint
+const DefaultBaseURL = "https://geocoding-api.open-meteo.com/v1/search"
+
+// DefaultLanguage is the language place names are returned in
+const DefaultLanguage = "en"
+
+// ErrNotFound is returned when the geocoder has no match for a query
+var ErrNotFound = errors.New("location not found")
+
+// GeoLocation represents a geographical point
+type GeoLocation struct {
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Name        string  `json:"name"`
+	Admin1      string  `json:"admin1,omitempty"` // state, province or region
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code,omitempty"`
+	Population  int     `json:"population,omitempty"`
+	Timezone    string  `json:"timezone,omitempty"`  // IANA name, e.g. "Europe/Paris"
+	Elevation   float64 `json:"elevation,omitempty"` // meters above sea level
+}
+
+// AmbiguousError is returned when several places match a query about equally
+// well. Candidates are in the geocoder's order of relevance.
+type AmbiguousError struct {
+	Query      string
+	Candidates []GeoLocation
+}
+
+// Error implements error, listing the candidates
+func (e *AmbiguousError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q matches %d places:\n", e.Query, len(e.Candidates))
+	for i, c := range e.Candidates {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, c.Summary())
+	}
+	b.WriteString("add a region or country to choose one, e.g. \"" + e.Candidates[0].Name)
+	if e.Candidates[0].Admin1 != "" {
+		b.WriteString(", " + e.Candidates[0].Admin1)
+	}
+	b.WriteString("\"")
+	return b.String()
+}
+
+// Geocoder resolves locations using Open-Meteo's geocoding endpoint
+type Geocoder struct {
+	BaseURL  string
+	HTTP     *api.Client
+	Language string // ISO 639-1 code for place names; empty means DefaultLanguage
+}
+
+// NewGeocoder returns a Geocoder using the public Open-Meteo endpoint
+func NewGeocoder() *Geocoder {
+	return &Geocoder{
+		BaseURL:  DefaultBaseURL,
+		HTTP:     api.DefaultClient,
+		Language: DefaultLanguage,
+	}
+}
+
+// splitShortPlusCode splits "CWC8+R9 Mountain View, CA" into the short
+// code and the locality it is relative to
+func splitShortPlusCode(location string) (string, string, bool) {
+	fields := strings.Fields(strings.TrimSpace(location))
+	if len(fields) < 2 {
+		return "", "", false
+	}
+	code := strings.TrimSuffix(fields[0], ",")
+	if !IsShortPlusCode(code) {
+		return "", "", false
+	}
+	return strings.ToUpper(code), strings.Join(fields[1:], " "), true
+}
+
+// lookupShortPlusCode geocodes the locality and recovers the full plus code
+// nearest to it
+func (g *Geocoder) lookupShortPlusCode(ctx context.Context, code, locality string) (GeoLocation, error) {
+	ref, err := g.Lookup(ctx, locality)
+	if err != nil {
+		return GeoLocation{}, err
+	}
+	full, err := RecoverPlusCode(code, ref.Latitude, ref.Longitude)
+	if err != nil {
+		return GeoLocation{}, err
+	}
+	lat, lon, err := DecodePlusCode(full)
+	if err != nil {
+		return GeoLocation{}, err
+	}
+
+	loc := coordinateLocation(lat, lon, full)
+	loc.Admin1, loc.Country, loc.CountryCode = ref.Admin1, ref.Country, ref.CountryCode
+	return loc, nil
+}
+
+// maxResults is how many candidates are requested when matching qualifiers
+const maxResults = 10
+
+// Lookup converts a ZIP/postal code, city name, coordinates or plus code to
+// a GeoLocation. Names may carry comma-separated qualifiers, as in
+// "Paris, Texas" or "Springfield, Illinois, United States", which are
+// matched against each candidate's region (admin1), country and country code.
+func (g *Geocoder) Lookup(ctx context.Context, location string) (GeoLocation, error) {
+	// Coordinates, geo: URIs and full plus codes are decoded locally
+	if loc, ok, err := ParseCoordinates(location); ok {
+		return loc, err
+	}
+	if code, locality, ok := splitShortPlusCode(location); ok {
+		return g.lookupShortPlusCode(ctx, code, locality)
+	}
+
+	name, qualifiers := ParseQuery(location)
+	if name == "" {
+		return GeoLocation{}, fmt.Errorf("%w: empty location", ErrNotFound)
+	}
+
+	results, err := g.Search(ctx, name, maxResults)
+	if err != nil {
+		return GeoLocation{}, err
+	}
+
+	var matches []GeoLocation
+	for _, result := range results {
+		if result.Matches(qualifiers) {
+			matches = append(matches, result)
+		}
+	}
+
+	switch {
+	case len(matches) == 0 && len(qualifiers) > 0 && len(results) > 0:
+		return GeoLocation{}, fmt.Errorf("%w: no %q in %s", ErrNotFound, name, strings.Join(qualifiers, ", "))
+	case len(matches) == 0:
+		return GeoLocation{}, ErrNotFound
+	case ambiguous(matches):
+		return GeoLocation{}, &AmbiguousError{Query: location, Candidates: matches}
+	}
+	return matches[0], nil
+}
+
+// dominance is how many times more populous the first match must be than
+// the second to be picked without asking, so "Paris" resolves to France
+// but "Springfield" does not resolve at all
+const dominance = 10
+
+// ambiguous reports whether several equally named matches are close enough
+// in population that picking the first would be a guess
+func ambiguous(matches []GeoLocation) bool {
+	if len(matches) < 2 || !strings.EqualFold(matches[0].Name, matches[1].Name) {
+		return false
+	}
+	return matches[0].Population < dominance*matches[1].Population || matches[0].Population == 0
+}
+
+// Search returns up to count candidates for a bare name or postal code
+func (g *Geocoder) Search(ctx context.Context, name string, count int) ([]GeoLocation, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("count", strconv.Itoa(count))
+	params.Set("language", g.language())
+	params.Set("format", "json")
+
+	resp, err := g.HTTP.Get(ctx, g.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.CheckResponse(resp.Response, resp.Body); err != nil {
+		return nil, fmt.Errorf("geocoding request failed: %w", err)
+	}
+
+	type GeoResponse struct {
+		Results []GeoLocation `json:"results"`
+	}
+
+	var geoResp GeoResponse
+	if err := json.Unmarshal(resp.Body, &geoResp); err != nil {
+		return nil, fmt.Errorf("could not parse geocoding response: %w", err)
+	}
+	return geoResp.Results, nil
+}
+
+// ParseQuery splits "City, Region, Country" into the name to search for and
+// the qualifiers that narrow down the results
+func ParseQuery(query string) (string, []string) {
+	var parts []string
+	for _, part := range strings.Split(query, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return parts[0], parts[1:]
+}
+
+// Matches reports whether every qualifier names the location's region,
+// country or country code
+func (l GeoLocation) Matches(qualifiers []string) bool {
+	for _, q := range qualifiers {
+		if !strings.EqualFold(q, l.Admin1) && !strings.EqualFold(q, l.Country) && !strings.EqualFold(q, l.CountryCode) {
+			return false
+		}
+	}
+	return true
+}
+
+// language returns the configured language or the default
+func (g *Geocoder) language() string {
+	if g.Language == "" {
+		return DefaultLanguage
+	}
+	return g.Language
+}
+
+// Summary describes the location with its population and coordinates, for
+// telling candidates apart
+func (l GeoLocation) Summary() string {
+	summary := l.String()
+	coords := fmt.Sprintf("%.4f, %.4f", l.Latitude, l.Longitude)
+	if l.Name == coords {
+		return summary
+	}
+	if l.Population > 0 {
+		summary += ", pop. " + formatPopulation(l.Population)
+	}
+	return fmt.Sprintf("%s (%s)", summary, coords)
+}
+
+// formatPopulation adds thousands separators
+func formatPopulation(n int) string {
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// NormalizeQuery lowercases a query and tidies its spacing, so equivalent
+// spellings share remembered choices and cache entries
+func NormalizeQuery(query string) string {
+	name, qualifiers := ParseQuery(query)
+	parts := append([]string{name}, qualifiers...)
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.Join(strings.Fields(part), " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String formats the location as "Name, Region, Country"
+func (l GeoLocation) String() string {
+	parts := []string{l.Name}
+	if l.Admin1 != "" && l.Admin1 != l.Name {
+		parts = append(parts, l.Admin1)
+	}
+	if l.Country != "" {
+		parts = append(parts, l.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Zone returns the location's time zone, or UTC when it is unknown
+func (l GeoLocation) Zone() *time.Location {
+	if l.Timezone == "" {
+		return time.UTC
+	}
+	zone, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return zone
+}
@@ -0,0 +1,101 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeGeocoder answers every search with two Parises and records the name
+func newFakeGeocoder(t *testing.T, gotName *string) *Geocoder {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotName = r.URL.Query().Get("name")
+		fmt.Fprint(w, `{"results":[
+			{"name":"Paris","latitude":48.85,"longitude":2.35,"admin1":"Île-de-France","country":"France","country_code":"FR","population":2138551},
+			{"name":"Paris","latitude":33.66,"longitude":-95.56,"admin1":"Texas","country":"United States","country_code":"US","population":24782}
+		]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	g := NewGeocoder()
+	g.BaseURL = server.URL
+	return g
+}
+
+func TestLookupQualifiers(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantLat float64
+	}{
+		{"Paris", 48.85},
+		{"Paris, France", 48.85},
+		{"paris, texas", 33.66},
+		{"Paris, Texas, US", 33.66},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			var name string
+			loc, err := newFakeGeocoder(t, &name).Lookup(context.Background(), tc.query)
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if name != "Paris" && name != "paris" {
+				t.Errorf("searched for %q; want the bare city name", name)
+			}
+			if loc.Latitude != tc.wantLat {
+				t.Errorf("latitude = %v; want %v", loc.Latitude, tc.wantLat)
+			}
+		})
+	}
+}
+
+func TestLookupNoQualifierMatch(t *testing.T) {
+	var name string
+	_, err := newFakeGeocoder(t, &name).Lookup(context.Background(), "Paris, Kentucky")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v; want ErrNotFound", err)
+	}
+}
+
+func TestLookupEncodesQuery(t *testing.T) {
+	var name string
+	if _, err := newFakeGeocoder(t, &name).Lookup(context.Background(), "São Paulo & Co, Brazil"); err == nil {
+		t.Fatal("Lookup matched a Paris for São Paulo")
+	}
+	if name != "São Paulo & Co" {
+		t.Errorf("server saw name %q; want it intact", name)
+	}
+}
+
+func TestLookupAmbiguous(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[
+			{"name":"Springfield","latitude":37.22,"longitude":-93.30,"admin1":"Missouri","country":"United States","population":169176},
+			{"name":"Springfield","latitude":39.80,"longitude":-89.64,"admin1":"Illinois","country":"United States","population":116250}
+		]}`)
+	}))
+	defer server.Close()
+	g := NewGeocoder()
+	g.BaseURL = server.URL
+
+	_, err := g.Lookup(context.Background(), "Springfield")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("err = %v; want both Springfields as candidates", err)
+	}
+
+	loc, err := g.Lookup(context.Background(), "Springfield, Illinois")
+	if err != nil || loc.Admin1 != "Illinois" {
+		t.Errorf("Lookup with region = %+v, %v; want Illinois", loc, err)
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	if got := NormalizeQuery("  New   York ,NY"); got != "new york, ny" {
+		t.Errorf("NormalizeQuery = %q; want %q", got, "new york, ny")
+	}
+}
@@ -0,0 +1,164 @@
+package geocode
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Open Location Code constants, see
+// https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	olcAlphabet     = "23456789CFGHJMPQRVWX"
+	olcSeparator    = '+'
+	olcSeparatorPos = 8
+	olcPadding      = '0'
+	olcPairLength   = 10
+	olcGridRows     = 5
+	olcGridColumns  = 4
+)
+
+// olcPairResolutions are the degrees covered by each digit pair
+var olcPairResolutions = []float64{20.0, 1.0, 0.05, 0.0025, 0.000125}
+
+// validPlusCode checks the shape of a full or short code
+func validPlusCode(code string) bool {
+	sep := strings.IndexRune(code, olcSeparator)
+	if sep < 0 || sep != strings.LastIndexByte(code, olcSeparator) || sep > olcSeparatorPos || sep%2 == 1 {
+		return false
+	}
+	if len(code)-sep-1 == 1 {
+		return false // a single digit after the separator is not allowed
+	}
+
+	padded := false
+	for i, r := range code {
+		switch {
+		case r == olcSeparator:
+		case r == olcPadding:
+			// Padding only appears before the separator, in whole pairs
+			if sep < olcSeparatorPos || i > sep || !padded && i%2 == 1 {
+				return false
+			}
+			padded = true
+		case strings.ContainsRune(olcAlphabet, r):
+			if padded {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return !padded || sep == len(code)-1
+}
+
+// IsFullPlusCode reports whether s is a full Open Location Code such as
+// "849VCWC8+R9"
+func IsFullPlusCode(s string) bool {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if !validPlusCode(code) || strings.IndexRune(code, olcSeparator) != olcSeparatorPos {
+		return false
+	}
+	// The first pair must fall within the valid latitude and longitude
+	if strings.IndexByte(olcAlphabet, code[0])*20 >= 180 {
+		return false
+	}
+	return len(code) < 2 || strings.IndexByte(olcAlphabet, code[1])*20 < 360
+}
+
+// IsShortPlusCode reports whether s is a plus code with leading digits
+// removed, such as "CWC8+R9", which needs a reference location
+func IsShortPlusCode(s string) bool {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	sep := strings.IndexRune(code, olcSeparator)
+	return validPlusCode(code) && sep >= 0 && sep < olcSeparatorPos
+}
+
+// DecodePlusCode returns the center of the area described by a full code
+func DecodePlusCode(s string) (float64, float64, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if !IsFullPlusCode(code) {
+		return 0, 0, fmt.Errorf("invalid plus code %q", s)
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r == olcSeparator || r == olcPadding {
+			return -1
+		}
+		return r
+	}, code)
+
+	lat, lon := -90.0, -180.0
+	latSize, lonSize := 0.0, 0.0
+	for i := 0; i < len(digits) && i < olcPairLength; i += 2 {
+		res := olcPairResolutions[i/2]
+		lat += float64(strings.IndexByte(olcAlphabet, digits[i])) * res
+		lon += float64(strings.IndexByte(olcAlphabet, digits[i+1])) * res
+		latSize, lonSize = res, res
+	}
+
+	// Digits past the tenth refine a 5x4 grid
+	for i := olcPairLength; i < len(digits); i++ {
+		latSize /= olcGridRows
+		lonSize /= olcGridColumns
+		v := strings.IndexByte(olcAlphabet, digits[i])
+		lat += float64(v/olcGridColumns) * latSize
+		lon += float64(v%olcGridColumns) * lonSize
+	}
+
+	return math.Min(lat+latSize/2, 90), lon + lonSize/2, nil
+}
+
+// RecoverPlusCode expands a short code to the full code nearest the
+// reference location
+func RecoverPlusCode(short string, refLat, refLon float64) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(short))
+	if !IsShortPlusCode(code) {
+		return "", fmt.Errorf("invalid short plus code %q", short)
+	}
+
+	paddingLength := olcSeparatorPos - strings.IndexRune(code, olcSeparator)
+	resolution := math.Pow(20, float64(2-paddingLength/2))
+	halfResolution := resolution / 2
+
+	// Borrow the leading digits from the reference location
+	full := encodePairs(refLat, refLon, paddingLength) + code
+	lat, lon, err := DecodePlusCode(full)
+	if err != nil {
+		return "", err
+	}
+
+	// The borrowed prefix may put the area one cell away from the reference
+	switch {
+	case refLat+halfResolution < lat && lat-resolution >= -90:
+		lat -= resolution
+	case refLat-halfResolution > lat && lat+resolution <= 90:
+		lat += resolution
+	}
+	switch {
+	case refLon+halfResolution < lon:
+		lon -= resolution
+	case refLon-halfResolution > lon:
+		lon += resolution
+	}
+
+	return encodePairs(lat, lon, paddingLength) + code, nil
+}
+
+// encodePairs returns the first n digits of the code for a location
+func encodePairs(lat, lon float64, n int) string {
+	lat = math.Min(math.Max(lat, -90), 90-1e-10) + 90
+	lon = math.Mod(math.Mod(lon+180, 360)+360, 360)
+
+	var b strings.Builder
+	for i := 0; i < n; i += 2 {
+		res := olcPairResolutions[i/2]
+		latDigit := int(lat / res)
+		lonDigit := int(lon / res)
+		lat -= float64(latDigit) * res
+		lon -= float64(lonDigit) * res
+		b.WriteByte(olcAlphabet[latDigit])
+		b.WriteByte(olcAlphabet[lonDigit])
+	}
+	return b.String()
+}
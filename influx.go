@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/render"
+)
+
+// influxWriter posts line protocol to an InfluxDB write endpoint, such as
+// /api/v2/write?org=...&bucket=... or a 1.x /write?db=...
+type influxWriter struct {
+	url   string
+	token string
+	http  *api.Client
+}
+
+// influxWriter returns the writer for -influx-url, or for the config's
+// influx_url with -format influx, or nil when output goes to stdout
+func (cmd *Command) influxWriter(config Config) *influxWriter {
+	if cmd.displayMode != DisplayInflux {
+		return nil
+	}
+	url := cmd.influxURL
+	if url == "" {
+		url = config.InfluxURL
+	}
+	if url == "" {
+		return nil
+	}
+	token := config.InfluxToken
+	if env := os.Getenv("INFLUX_TOKEN"); env != "" {
+		token = env
+	}
+	return &influxWriter{url: url, token: token, http: api.DefaultClient}
+}
+
+// write posts the sections' points and reports how many were written to w
+func (iw *influxWriter) write(ctx context.Context, w io.Writer, sections []render.Section, opts render.Options) error {
+	var buf bytes.Buffer
+	if err := render.Influx(&buf, sections, opts); err != nil {
+		return err
+	}
+
+	header := http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+	if iw.token != "" {
+		header.Set("Authorization", "Token "+iw.token)
+	}
+	resp, err := iw.http.Post(ctx, iw.url, header, buf.Bytes())
+	if err != nil {
+		// The request error quotes the URL, credentials and all
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("error writing to InfluxDB at %s: %w", redactURL(iw.url), urlErr.Err)
+		}
+		return fmt.Errorf("error writing to InfluxDB: %w", err)
+	}
+	if err := api.CheckResponse(resp.Response, resp.Body); err != nil {
+		return fmt.Errorf("error writing to InfluxDB: %w", err)
+	}
+	fmt.Fprintf(w, "Wrote %d points to %s\n", bytes.Count(buf.Bytes(), []byte{'\n'}), redactURL(iw.url))
+	return nil
+}
+
+// redactURL drops the userinfo and query from raw, which for InfluxDB 1.x can
+// carry the credentials (u= and p=), so the URL is safe to print
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "the InfluxDB URL"
+	}
+	u.User, u.RawQuery, u.ForceQuery, u.Fragment = nil, "", false, ""
+	return u.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/weather"
+)
+
+// resolveLocation geocodes query. When several places match and prompt is
+// set, an interactive user is asked to pick one and the choice is remembered
+// in the config; otherwise the candidates are returned as an error. Commands
+// that keep running or run unattended pass prompt false, so they never block
+// on the picker.
+func resolveLocation(ctx context.Context, client *weather.Client, config *Config, query string, prompt bool) (geocode.GeoLocation, error) {
+	key := geocode.NormalizeQuery(query)
+	if location, ok := config.Choices[key]; ok {
+		return location, nil
+	}
+
+	location, err := client.Locate(ctx, query)
+	var ambiguous *geocode.AmbiguousError
+	if !errors.As(err, &ambiguous) || !prompt || !isTerminal(os.Stdin) {
+		return location, err
+	}
+
+	location, err = pickLocation(os.Stdin, os.Stderr, ambiguous)
+	if err != nil {
+		return geocode.GeoLocation{}, err
+	}
+
+	// Remember the choice so we don't ask again
+	if config.Choices == nil {
+		config.Choices = map[string]geocode.GeoLocation{}
+	}
+	config.Choices[key] = location
+	if err := saveConfig(*config); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to remember location choice: %v\n", err)
+	}
+	return location, nil
+}
+
+// pickLocation shows a numbered list of candidates and reads the user's choice
+func pickLocation(in io.Reader, out io.Writer, ambiguous *geocode.AmbiguousError) (geocode.GeoLocation, error) {
+	candidates := ambiguous.Candidates
+	fmt.Fprintf(out, "Several places match %q:\n", ambiguous.Query)
+	for i, c := range candidates {
+		fmt.Fprintf(out, "  %d. %s\n", i+1, c.Summary())
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "Choose a location [1-%d]: ", len(candidates))
+		line, err := reader.ReadString('\n')
+		if choice, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && choice >= 1 && choice <= len(candidates) {
+			return candidates[choice-1], nil
+		}
+		if err != nil {
+			return geocode.GeoLocation{}, fmt.Errorf("no location chosen: %w", ambiguous)
+		}
+		fmt.Fprintf(out, "Please enter a number between 1 and %d\n", len(candidates))
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
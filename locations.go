@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+// SavedLocation is an entry in the named location book
+type SavedLocation struct {
+	Location geocode.GeoLocation `json:"location"`
+	Units    forecast.UnitSystem `json:"units,omitempty"` // overrides the default units
+}
+
+// savedLocation looks up a location book entry by its case-insensitive name
+func (c *Config) savedLocation(name string) (SavedLocation, bool) {
+	entry, ok := c.Locations[strings.ToLower(strings.TrimSpace(name))]
+	return entry, ok
+}
+
+// runLocations manages the named location book
+func runLocations(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	config := loadConfig()
+	switch args[0] {
+	case "list", "ls":
+		listLocations(os.Stdout, config)
+		return nil
+
+	case "add":
+		fs := flag.NewFlagSet("locations add", flag.ContinueOnError)
+		units := fs.String("units", "", "Units for this location (metric or imperial)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() < 2 {
+			return fmt.Errorf("usage: locations add [-units system] <name> <location>")
+		}
+		return addLocation(ctx, &config, fs.Arg(0), strings.Join(fs.Args()[1:], " "), forecast.UnitSystem(*units))
+
+	case "rename", "mv":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: locations rename <old> <new>")
+		}
+		return renameLocation(&config, args[1], args[2])
+
+	case "group":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: locations group <name> [location;location...]")
+		}
+		return setGroup(&config, args[1], strings.Join(args[2:], " "))
+
+	case "remove", "rm":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: locations remove <name>")
+		}
+		key := strings.ToLower(args[1])
+		if _, ok := config.Locations[key]; !ok {
+			return fmt.Errorf("no saved location named %q", args[1])
+		}
+		delete(config.Locations, key)
+		if err := saveConfig(config); err != nil {
+			return fmt.Errorf("error saving config: %w", err)
+		}
+		fmt.Printf("Removed %s\n", key)
+		return nil
+	}
+	return fmt.Errorf("unknown locations command %q (use list, add, group, rename or remove)", args[0])
+}
+
+// addLocation resolves query and saves it under name
+func addLocation(ctx context.Context, config *Config, name, query string, units forecast.UnitSystem) error {
+	key, err := locationName(name)
+	if err != nil {
+		return err
+	}
+	if units != "" && units != forecast.UnitMetric && units != forecast.UnitImperial {
+		return fmt.Errorf("unknown unit system %q (use metric or imperial)", units)
+	}
+	if err := configureHTTP(*config, &Command{retries: -1}); err != nil {
+		return err
+	}
+
+	location, err := resolveLocation(ctx, newClient(*config), config, query, true)
+	if err != nil {
+		return fmt.Errorf("could not get coordinates: %w", err)
+	}
+
+	if config.Locations == nil {
+		config.Locations = map[string]SavedLocation{}
+	}
+	config.Locations[key] = SavedLocation{Location: location, Units: units}
+	if err := saveConfig(*config); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+	fmt.Printf("Saved %s: %s\n", key, location.Summary())
+	return nil
+}
+
+// renameLocation moves a location book entry to a new name
+func renameLocation(config *Config, from, to string) error {
+	fromKey := strings.ToLower(from)
+	entry, ok := config.Locations[fromKey]
+	if !ok {
+		return fmt.Errorf("no saved location named %q", from)
+	}
+	toKey, err := locationName(to)
+	if err != nil {
+		return err
+	}
+	if _, exists := config.Locations[toKey]; exists {
+		return fmt.Errorf("a location named %q already exists", toKey)
+	}
+
+	delete(config.Locations, fromKey)
+	config.Locations[toKey] = entry
+	if err := saveConfig(*config); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+	fmt.Printf("Renamed %s to %s\n", fromKey, toKey)
+	return nil
+}
+
+// setGroup saves a ";"-separated list of locations under name, or removes
+// the group when the list is empty
+func setGroup(config *Config, name, members string) error {
+	key, err := locationName(name)
+	if err != nil {
+		return err
+	}
+
+	queries := splitLocations(members)
+	if len(queries) == 0 {
+		if _, ok := config.Groups[key]; !ok {
+			return fmt.Errorf("no location group named %q", name)
+		}
+		delete(config.Groups, key)
+	} else {
+		if config.Groups == nil {
+			config.Groups = map[string][]string{}
+		}
+		config.Groups[key] = queries
+	}
+	if err := saveConfig(*config); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	if len(queries) == 0 {
+		fmt.Printf("Removed group %s\n", key)
+	} else {
+		fmt.Printf("Saved group %s: %s\n", key, strings.Join(queries, "; "))
+	}
+	return nil
+}
+
+// listLocations prints the location book to w
+func listLocations(w io.Writer, config Config) {
+	if len(config.Locations) == 0 && len(config.Groups) == 0 {
+		fmt.Fprintln(w, "No saved locations. Add one with: locations add <name> <location>")
+		return
+	}
+
+	names := make([]string, 0, len(config.Locations))
+	for name := range config.Locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entry := config.Locations[name]
+		fmt.Fprintf(w, "%-12s %s\n", name, entry.Location.Summary())
+		if entry.Location.Timezone != "" {
+			fmt.Fprintf(w, "%-12s timezone: %s\n", "", entry.Location.Timezone)
+		}
+		if entry.Units != "" {
+			fmt.Fprintf(w, "%-12s units: %s\n", "", forecast.UnitSystemName(entry.Units))
+		}
+	}
+
+	groups := make([]string, 0, len(config.Groups))
+	for name := range config.Groups {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+
+	if len(groups) > 0 {
+		fmt.Fprintln(w, "\nGroups:")
+	}
+	for _, name := range groups {
+		fmt.Fprintf(w, "%-12s %s\n", name, strings.Join(config.Groups[name], "; "))
+	}
+}
+
+// locationName validates and normalizes a location book name
+func locationName(name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	// -z splits lists on ";", so such a name could never be used
+	if key == "" || strings.ContainsAny(key, ",; ") || strings.HasPrefix(key, "-") {
+		return "", fmt.Errorf("invalid location name %q: use a single word such as home or work", name)
+	}
+	if _, ok, _ := geocode.ParseCoordinates(key); ok {
+		return "", fmt.Errorf("invalid location name %q: it looks like coordinates", name)
+	}
+	return key, nil
+}
+
+// printLocationsHelp lists the locations commands in the main help text
+func printLocationsHelp() {
+	fmt.Printf("  locations [list]                       List saved locations\n")
+	fmt.Printf("  locations add [-units u] <name> <loc>  Save a location under a name\n")
+	fmt.Printf("  locations group <name> <loc;loc...>    Save a group of locations to compare\n")
+	fmt.Printf("  locations rename <old> <new>           Rename a saved location\n")
+	fmt.Printf("  locations remove <name>                Remove a saved location\n\n")
+}
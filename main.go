@@ -1,17 +1,25 @@
 package main
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
+
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
 )
 
 // Application constants
@@ -29,44 +37,180 @@ type DisplayMode string
 const (
 	DisplayText  DisplayMode = "text"
 	DisplayTable DisplayMode = "table"
+	DisplayJSON  DisplayMode = "json" // versioned schema, see render.Report
+	DisplayCSV   DisplayMode = "csv"
+	DisplayTSV   DisplayMode = "tsv"
+
+	// Time-series formats
+	DisplayInflux      DisplayMode = "influx"      // InfluxDB line protocol
+	DisplayOpenMetrics DisplayMode = "openmetrics" // timestamped OpenMetrics text
+
+	// Status bar formats; see render.StatusBar
+	DisplayWaybar   DisplayMode = render.StatusWaybar
+	DisplayI3Blocks DisplayMode = render.StatusI3Blocks
+	DisplayI3Bar    DisplayMode = render.StatusI3Bar
+	DisplayPolybar  DisplayMode = render.StatusPolybar
+	DisplayTmux     DisplayMode = render.StatusTmux
 )
 
-// UnitSystem represents measurement units to use
-type UnitSystem string
+// displayModes lists the modes accepted by -format
+var displayModes = []DisplayMode{DisplayText, DisplayTable, DisplayJSON, DisplayCSV, DisplayTSV,
+	DisplayInflux, DisplayOpenMetrics, DisplayWaybar, DisplayI3Blocks, DisplayI3Bar, DisplayPolybar, DisplayTmux}
 
-// Available unit systems
-const (
-	UnitMetric   UnitSystem = "metric"
-	UnitImperial UnitSystem = "imperial"
-)
+// isStatusBar reports whether mode is one of the status bar formats
+func (mode DisplayMode) isStatusBar() bool {
+	switch mode {
+	case DisplayWaybar, DisplayI3Blocks, DisplayI3Bar, DisplayPolybar, DisplayTmux:
+		return true
+	}
+	return false
+}
+
+// parseDisplayMode validates a -format value
+func parseDisplayMode(s string) (DisplayMode, error) {
+	mode := DisplayMode(strings.ToLower(strings.TrimSpace(s)))
+	for _, m := range displayModes {
+		if mode == m {
+			return mode, nil
+		}
+	}
+	names := make([]string, len(displayModes))
+	for i, m := range displayModes {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unknown format %q (use %s)", s, strings.Join(names, ", "))
+}
 
 // Config stores user preferences
 type Config struct {
-	ZipCode     string      `json:"zip_code"`
-	DisplayMode DisplayMode `json:"display_mode"`
-	Units       UnitSystem  `json:"units"`
-	UseColors   bool        `json:"use_colors"`
+	ZipCode     string              `json:"zip_code"`
+	DisplayMode DisplayMode         `json:"display_mode"`
+	Units       forecast.UnitSystem `json:"units"`
+	UseColors   bool                `json:"use_colors"`
+	Provider    string              `json:"provider,omitempty"`
+	Providers   []string            `json:"providers,omitempty"` // failover order
+	Consensus   string              `json:"consensus_method,omitempty"`
+	Timeout     string              `json:"timeout,omitempty"` // per-request, e.g. "10s"
+	Retries     *int                `json:"retries,omitempty"`
+
+	// Choices remembers which place the user picked for ambiguous queries
+	Choices map[string]geocode.GeoLocation `json:"location_choices,omitempty"`
+
+	// Locations is the named location book (home, work, ...)
+	Locations map[string]SavedLocation `json:"locations,omitempty"`
+
+	// Language for place names from the geocoder (ISO 639-1, default "en")
+	Language string `json:"language,omitempty"`
+
+	// Templates are named output templates for -template
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// Groups names lists of locations shown side by side
+	Groups map[string][]string `json:"location_groups,omitempty"`
+
+	// InfluxDB write endpoint for -format influx; the token may instead
+	// come from $INFLUX_TOKEN
+	InfluxURL   string `json:"influx_url,omitempty"`
+	InfluxToken string `json:"influx_token,omitempty"`
+
+	// MQTT configures publish-mqtt
+	MQTT *MQTTConfig `json:"mqtt,omitempty"`
+
+	// Notify maps locations, as given to -zip, to their notify targets
+	Notify map[string]NotifyConfig `json:"notify,omitempty"`
+}
+
+// Main function - entry point for the application
+func main() {
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	if len(os.Args) > 1 && subcommands[os.Args[1]] != nil {
+		err = subcommands[os.Args[1]](ctx, os.Args[2:])
+	} else {
+		// Parse command line flags and handle commands
+		var cmd *Command
+		if cmd, err = parseFlags(os.Args[1:]); err == nil {
+			err = cmd.execute(ctx)
+		}
+	}
+
+	if err != nil {
+		stop()
+		var status exitStatus
+		if !errors.As(err, &status) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(exitCode(err))
+	}
 }
 
-// ANSI color codes
+// subcommands maps a leading command-line word to its handler
+var subcommands = map[string]func(ctx context.Context, args []string) error{
+	"locations":    runLocations,
+	"cache":        runCache,
+	"serve":        runServe,
+	"publish-mqtt": runPublishMQTT,
+	"check":        runCheck,
+	"notify":       runNotify,
+}
+
+// Exit codes, so scripts can tell failures apart
 const (
-	colorReset   = "\033[0m"
-	colorRed     = "\033[31m"
-	colorGreen   = "\033[32m"
-	colorYellow  = "\033[33m"
-	colorBlue    = "\033[34m"
-	colorMagenta = "\033[35m"
-	colorCyan    = "\033[36m"
-	colorWhite   = "\033[37m"
+	exitError       = 1 // any other failure
+	exitNotFound    = 3 // location could not be geocoded
+	exitBadRequest  = 4 // API rejected the request
+	exitRateLimited = 5 // API rate limit reached
+	exitServerError = 6 // API server error
+	exitAmbiguous   = 7 // several places match the location
+	exitInterrupted = 130
+
+	exitRuleFired  = 1 // check: a rule fired
+	exitCheckError = 2 // check: any other failure, so it never reads as a fired rule
 )
 
-// Main function - entry point for the application
-func main() {
-	// Parse command line flags and handle commands
-	cmd := parseFlags()
-	if err := cmd.execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+// exitStatus ends the program with its code and no error message, for
+// commands whose exit status is their answer
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// codeError reports err and ends the program with code
+type codeError struct {
+	err  error
+	code int
+}
+
+func (e codeError) Error() string { return e.err.Error() }
+func (e codeError) Unwrap() error { return e.err }
+
+// exitCode maps an error to the process exit status
+func exitCode(err error) int {
+	var status exitStatus
+	var coded codeError
+	switch {
+	case errors.As(err, &status):
+		return int(status)
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, geocode.ErrNotFound):
+		return exitNotFound
+	case errors.As(err, new(*geocode.AmbiguousError)):
+		return exitAmbiguous
+	case errors.Is(err, api.ErrBadRequest):
+		return exitBadRequest
+	case errors.Is(err, api.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, api.ErrServer):
+		return exitServerError
+	default:
+		return exitError
 	}
 }
 
@@ -75,68 +219,98 @@ type Command struct {
 	showHelp       bool
 	showDaily      bool
 	showHourly     bool
-	zipOverride    string
+	zipOverride    locationList
 	displayMode    DisplayMode
+	format         string
+	columns        string
+	template       string
+	templateFile   string
+	watch          watchFlag
+	tui            bool
+	influxURL      string
 	forceTextMode  bool
 	forceTableMode bool
-	unitSystem     UnitSystem
+	unitSystem     forecast.UnitSystem
 	useColors      *bool
 	noColors       bool
 	saveAll        bool // New flag to save all settings
+	provider       string
+	timeout        time.Duration
+	retries        int
+	consensus      bool
+	consensusBy    string
 }
 
 // parseFlags processes command-line arguments and returns a Command
-func parseFlags() *Command {
+func parseFlags(args []string) (*Command, error) {
 	cmd := &Command{}
+	fs := flag.NewFlagSet("go-weather", flag.ContinueOnError)
 
 	// Define command line flags
-	flag.BoolVar(&cmd.showHelp, "help", false, "Show help information")
-	flag.BoolVar(&cmd.showDaily, "daily", false, "Show 7-day forecast")
-	flag.BoolVar(&cmd.showHourly, "hourly", false, "Show hourly forecast")
-	flag.StringVar(&cmd.zipOverride, "zip", "", "Override default ZIP/postal code")
-	flag.BoolVar(&cmd.forceTableMode, "table", false, "Show output in table format")
-	flag.BoolVar(&cmd.forceTextMode, "text", false, "Show output in text format")
-	flag.StringVar((*string)(&cmd.unitSystem), "units", "", "Use specific units (metric or imperial)")
+	fs.BoolVar(&cmd.showHelp, "help", false, "Show help information")
+	fs.BoolVar(&cmd.showDaily, "daily", false, "Show 7-day forecast")
+	fs.BoolVar(&cmd.showHourly, "hourly", false, "Show hourly forecast")
+	fs.Var(&cmd.zipOverride, "zip", "Override default location; repeat or separate with ; to compare several")
+	fs.BoolVar(&cmd.forceTableMode, "table", false, "Show output in table format")
+	fs.BoolVar(&cmd.forceTextMode, "text", false, "Show output in text format")
+	fs.StringVar(&cmd.format, "format", "", "Output format (text, table, json, csv, tsv, influx, openmetrics, waybar, i3blocks, i3bar, polybar or tmux)")
+	fs.StringVar(&cmd.columns, "columns", "", "Comma-separated columns for csv and tsv output")
+	fs.StringVar(&cmd.template, "template", "", "Go text/template, or the name of one saved in the config")
+	fs.StringVar(&cmd.templateFile, "template-file", "", "File holding a Go text/template")
+	fs.StringVar(&cmd.influxURL, "influx-url", "", "POST -format influx output to this InfluxDB write endpoint")
+	fs.BoolVar(&cmd.tui, "tui", false, "Browse forecasts for saved locations in a full-screen terminal UI")
+	fs.Var(&cmd.watch, "watch", "Keep running and refresh when the data expires, or every interval with -watch=10m")
+	fs.StringVar((*string)(&cmd.unitSystem), "units", "", "Use specific units (metric or imperial)")
+	fs.DurationVar(&cmd.timeout, "timeout", 0, "Timeout for each API request (e.g. 10s)")
+	fs.IntVar(&cmd.retries, "retries", -1, "Retries for transient API failures")
+	fs.BoolVar(&cmd.consensus, "consensus", false, "Blend forecasts from all configured providers")
+	fs.StringVar(&cmd.consensusBy, "consensus-method", "", "Consensus blending method (median or mean)")
+	fs.StringVar(&cmd.provider, "provider", "", "Weather data provider, or comma-separated failover order (open-meteo, met-norway, nws)")
 
 	// Add save flag
-	flag.BoolVar(&cmd.saveAll, "save", false, "Save current settings as defaults")
+	fs.BoolVar(&cmd.saveAll, "save", false, "Save current settings as defaults")
 
 	var useColors bool
-	flag.BoolVar(&useColors, "color", false, "Enable colored output")
-	flag.BoolVar(&cmd.noColors, "no-color", false, "Disable colored output")
+	fs.BoolVar(&useColors, "color", false, "Enable colored output")
+	fs.BoolVar(&cmd.noColors, "no-color", false, "Disable colored output")
 
 	// Add short flag aliases
-	flag.BoolVar(&cmd.showHelp, "?", false, "Short for -help")
-	flag.BoolVar(&cmd.showDaily, "d", false, "Short for -daily")
-	flag.BoolVar(&cmd.showHourly, "h", false, "Short for -hourly")
-	flag.StringVar(&cmd.zipOverride, "z", "", "Short for -zip")
-	flag.BoolVar(&cmd.forceTableMode, "t", false, "Short for -table")
-	flag.BoolVar(&cmd.forceTextMode, "T", false, "Short for -text")
-	flag.StringVar((*string)(&cmd.unitSystem), "u", "", "Short for -units")
-	flag.BoolVar(&useColors, "c", false, "Short for -color")
-	flag.BoolVar(&cmd.noColors, "nc", false, "Short for -no-color")
-	flag.BoolVar(&cmd.saveAll, "s", false, "Short for -save")
+	fs.BoolVar(&cmd.showHelp, "?", false, "Short for -help")
+	fs.BoolVar(&cmd.showDaily, "d", false, "Short for -daily")
+	fs.BoolVar(&cmd.showHourly, "h", false, "Short for -hourly")
+	fs.Var(&cmd.zipOverride, "z", "Short for -zip")
+	fs.BoolVar(&cmd.forceTableMode, "t", false, "Short for -table")
+	fs.BoolVar(&cmd.forceTextMode, "T", false, "Short for -text")
+	fs.StringVar((*string)(&cmd.unitSystem), "u", "", "Short for -units")
+	fs.BoolVar(&useColors, "c", false, "Short for -color")
+	fs.BoolVar(&cmd.noColors, "nc", false, "Short for -no-color")
+	fs.BoolVar(&cmd.saveAll, "s", false, "Short for -save")
 
 	// Override default usage output
-	flag.Usage = printHelp
+	fs.Usage = printHelp
 
-	// Parse flags and capture original args before parsing
-	originalArgs := os.Args[1:]
-	flag.Parse()
+	// The flag package has already reported a bad flag; exit as
+	// flag.ExitOnError would
+	if err := fs.Parse(args); err != nil {
+		return nil, exitStatus(2)
+	}
+	if err := noArgs(fs.Args(), cmd.watch); err != nil {
+		return nil, err
+	}
 
 	// Process color flags manually by checking if they appeared in the args
-	for _, arg := range originalArgs {
+	for _, arg := range args {
 		if arg == "-color" || arg == "-c" {
 			cmd.useColors = &useColors
 			break
 		}
 	}
 
-	return cmd
+	return cmd, nil
 }
 
 // execute runs the command based on flags
-func (cmd *Command) execute() error {
+func (cmd *Command) execute(ctx context.Context) error {
 	if cmd.showHelp {
 		printHelp()
 		return nil
@@ -152,6 +326,15 @@ func (cmd *Command) execute() error {
 	} else if cmd.forceTextMode && !cmd.forceTableMode {
 		displayMode = DisplayText
 	}
+	if cmd.format != "" {
+		mode, err := parseDisplayMode(cmd.format)
+		if err != nil {
+			return err
+		}
+		displayMode = mode
+	} else if cmd.influxURL != "" {
+		displayMode = DisplayInflux
+	}
 
 	// If no display mode is set, default to text
 	if displayMode == "" {
@@ -168,7 +351,7 @@ func (cmd *Command) execute() error {
 
 	// If no unit system is set, default to metric
 	if unitSystem == "" {
-		unitSystem = UnitMetric
+		unitSystem = forecast.UnitMetric
 	}
 
 	// Handle color settings
@@ -179,17 +362,38 @@ func (cmd *Command) execute() error {
 		useColors = false
 	}
 
+	// Determine weather provider or failover chain
+	provider, err := cmd.newProvider(config)
+	if err != nil {
+		return err
+	}
+
+	// Configure the shared HTTP client
+	if err := configureHTTP(config, cmd); err != nil {
+		return err
+	}
+
 	// Get location coordinates
-	zipCode := cmd.zipOverride
+	zipCode := cmd.zipOverride.String()
 	if zipCode == "" {
 		zipCode = config.ZipCode
 	}
 
 	// If no zip code is set, ask the user
 	if zipCode == "" {
-		fmt.Print("Enter your location (ZIP/postal code or city name): ")
+		fmt.Fprint(os.Stderr, "Enter your location (ZIP/postal code or city name): ")
 		fmt.Scanln(&zipCode)
 	}
+	queries := config.expandLocations(splitLocations(zipCode))
+	if len(queries) == 0 {
+		return fmt.Errorf("no location given")
+	}
+
+	// Named locations come from the location book, with their own units
+	saved, isSaved := config.savedLocation(queries[0])
+	if len(queries) == 1 && isSaved && cmd.unitSystem == "" && saved.Units != "" {
+		unitSystem = saved.Units
+	}
 
 	// Handle saving settings if --save flag is provided
 	if cmd.saveAll {
@@ -199,7 +403,7 @@ func (cmd *Command) execute() error {
 		}
 
 		// Save display mode if explicitly set
-		if cmd.forceTableMode || cmd.forceTextMode {
+		if cmd.forceTableMode || cmd.forceTextMode || cmd.format != "" {
 			config.DisplayMode = displayMode
 		}
 
@@ -208,6 +412,25 @@ func (cmd *Command) execute() error {
 			config.Units = unitSystem
 		}
 
+		// Save provider if explicitly set
+		if cmd.provider != "" {
+			config.Provider = ""
+			config.Providers = providerList(cmd.provider)
+		}
+
+		// Save network settings if explicitly set
+		if cmd.timeout > 0 {
+			config.Timeout = cmd.timeout.String()
+		}
+		if cmd.retries >= 0 {
+			config.Retries = &cmd.retries
+		}
+
+		// Save consensus method if explicitly set
+		if cmd.consensusBy != "" {
+			config.Consensus = cmd.consensusBy
+		}
+
 		// Save color preference if explicitly set
 		if cmd.useColors != nil {
 			config.UseColors = *cmd.useColors
@@ -220,39 +443,293 @@ func (cmd *Command) execute() error {
 			return fmt.Errorf("error saving config: %w", err)
 		}
 
-		fmt.Println("All settings saved:")
-		fmt.Printf("- Location: %s\n", config.ZipCode)
-		fmt.Printf("- Display mode: %s\n", config.DisplayMode)
-		fmt.Printf("- Unit system: %s\n", getUnitSystemName(config.Units))
-		fmt.Printf("- Colors: %v\n", config.UseColors)
+		fmt.Fprintln(os.Stderr, "All settings saved:")
+		fmt.Fprintf(os.Stderr, "- Location: %s\n", config.ZipCode)
+		fmt.Fprintf(os.Stderr, "- Display mode: %s\n", config.DisplayMode)
+		fmt.Fprintf(os.Stderr, "- Unit system: %s\n", forecast.UnitSystemName(config.Units))
+		fmt.Fprintf(os.Stderr, "- Colors: %v\n", config.UseColors)
+		if len(config.Providers) > 0 {
+			fmt.Fprintf(os.Stderr, "- Providers: %s\n", strings.Join(config.Providers, ", "))
+		} else if config.Provider != "" {
+			fmt.Fprintf(os.Stderr, "- Provider: %s\n", config.Provider)
+		}
+	}
+
+	client := newClient(config)
+	client.Provider = provider
+
+	// Status bar tooltips summarize the coming hours and days
+	if cmd.displayMode.isStatusBar() {
+		cmd.showDaily, cmd.showHourly = true, true
+	}
+
+	opts := forecast.Options{
+		Daily:  cmd.showDaily,
+		Hourly: cmd.showHourly,
+		Units:  unitSystem,
+	}
+	renderOpts := render.Options{
+		Daily:  cmd.showDaily,
+		Hourly: cmd.showHourly,
+		Units:  unitSystem,
+		Colors: useColors,
+	}
+	if cmd.columns != "" {
+		for _, c := range strings.Split(cmd.columns, ",") {
+			renderOpts.Columns = append(renderOpts.Columns, strings.TrimSpace(c))
+		}
+	}
+	// Parse a user template before fetching so mistakes fail fast
+	tmpl, err := cmd.loadTemplate(config, renderOpts)
+	if err != nil {
+		return err
+	}
+
+	draw := func(w io.Writer, sections []render.Section) error {
+		if tmpl != nil {
+			return tmpl.Execute(w, sections)
+		}
+		return displayWeatherData(w, sections, renderOpts, cmd.displayMode)
+	}
+
+	// Line protocol can go straight to an InfluxDB write endpoint
+	if influx := cmd.influxWriter(config); influx != nil && tmpl == nil {
+		draw = func(w io.Writer, sections []render.Section) error {
+			return influx.write(ctx, w, sections, renderOpts)
+		}
+	}
+
+	// i3bar reads one endless stream, best kept open with -watch
+	if cmd.displayMode == DisplayI3Bar && tmpl == nil {
+		if err := render.I3BarHeader(os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if cmd.watch.enabled {
+		return cmd.runWatch(ctx, client, &config, queries, opts, draw)
+	}
+	if cmd.tui {
+		return runTUI(ctx, client, &config, queries, renderOpts)
+	}
+
+	_, showSource := provider.(*forecast.Chain)
+	sections, err := fetchSections(ctx, client, &config, queries, opts, os.Stderr, showSource, true)
+	if err != nil {
+		return err
+	}
+
+	// Display the weather data
+	return draw(os.Stdout, sections)
+}
+
+// runWatch redraws the report until interrupted, refreshing it when the
+// cached forecasts expire or on the -watch interval
+func (cmd *Command) runWatch(ctx context.Context, client *weather.Client, config *Config, queries []string, opts forecast.Options,
+	draw func(io.Writer, []render.Section) error) error {
+	// A shorter interval than the cache lifetime must still fetch fresh data
+	if cmd.watch.interval > 0 && cmd.watch.interval < client.Cache.TTL {
+		client.Cache.TTL = cmd.watch.interval
+	}
+
+	// Status bars read each update as it comes, without the footer
+	bare := cmd.displayMode.isStatusBar() && cmd.template == "" && cmd.templateFile == ""
+	w := &watcher{
+		out:      os.Stdout,
+		redraw:   isTerminal(os.Stdout) && !bare,
+		bare:     bare,
+		log:      os.Stderr,
+		interval: cmd.watch.interval,
+		draw:     draw,
+		fetch: func(ctx context.Context) ([]render.Section, error) {
+			return fetchSections(ctx, client, config, queries, opts, io.Discard, false, false)
+		},
+		expires: func(sections []render.Section) time.Time {
+			return firstExpiry(client, sections, opts)
+		},
+	}
+	return w.run(ctx)
+}
+
+// loadTemplate returns the template chosen with -template or -template-file,
+// or nil when neither is given
+func (cmd *Command) loadTemplate(config Config, opts render.Options) (*render.Template, error) {
+	text := cmd.template
+	if named, ok := config.Templates[cmd.template]; ok {
+		text = named
+	}
+	if cmd.templateFile != "" {
+		data, err := os.ReadFile(cmd.templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+	return render.NewTemplate(text, opts)
+}
+
+// newProvider builds the configured provider, failover chain or consensus,
+// with -provider and -consensus-method taking precedence over the config
+func (cmd *Command) newProvider(config Config) (forecast.Provider, error) {
+	providerNames := config.Providers
+	if len(providerNames) == 0 && config.Provider != "" {
+		providerNames = []string{config.Provider}
+	}
+	if cmd.provider != "" {
+		providerNames = providerList(cmd.provider)
+	}
+	consensusMethod := config.Consensus
+	if cmd.consensusBy != "" {
+		consensusMethod = cmd.consensusBy
+	}
+
+	if cmd.consensus {
+		consensus, err := forecast.NewConsensus(providerNames, consensusMethod)
+		if err != nil {
+			return nil, err
+		}
+		consensus.Log = os.Stderr
+		return consensus, nil
 	}
 
-	// Get geographical coordinates
-	latitude, longitude, err := getCoordinates(zipCode)
+	chain, err := forecast.NewChain(providerNames)
 	if err != nil {
-		return fmt.Errorf("could not get coordinates: %w", err)
+		return nil, err
+	}
+	if c, ok := chain.(*forecast.Chain); ok {
+		c.Log = os.Stderr
+	}
+	return chain, nil
+}
+
+// providerList splits a comma-separated -provider value, dropping blanks
+func providerList(s string) []string {
+	var out []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// newClient returns a weather client using the configured caches and language
+func newClient(config Config) *weather.Client {
+	client := weather.NewClient()
+	client.Cache.TTL = cacheDuration
+	client.Log = os.Stderr
+	if config.Language != "" {
+		client.Geocoder.Language = config.Language
+	}
+	return client
+}
+
+// Display weather data in appropriate format; several locations are
+// compared side by side
+func displayWeatherData(w io.Writer, sections []render.Section, opts render.Options, mode DisplayMode) error {
+	switch {
+	case mode == DisplayJSON:
+		return render.JSON(w, sections, opts)
+	case mode == DisplayCSV:
+		return render.CSV(w, sections, opts, ',')
+	case mode == DisplayTSV:
+		return render.CSV(w, sections, opts, '\t')
+	case mode == DisplayInflux:
+		return render.Influx(w, sections, opts)
+	case mode == DisplayOpenMetrics:
+		return render.OpenMetrics(w, sections, opts)
+	case mode.isStatusBar():
+		return render.StatusBar(w, sections, opts, string(mode))
+	case len(sections) > 1 && mode == DisplayTable:
+		render.CompareTable(w, sections, opts)
+	case len(sections) > 1:
+		render.CompareText(w, sections, opts)
+	case mode == DisplayTable:
+		opts.Zone = sections[0].Location.Zone()
+		render.Table(w, sections[0].Weather, opts)
+	default:
+		opts.Zone = sections[0].Location.Zone()
+		render.Text(w, sections[0].Weather, opts)
+	}
+	return nil
+}
+
+// configureHTTP applies timeout and retry settings to the HTTP client shared
+// by the geocoder and providers
+func configureHTTP(config Config, cmd *Command) error {
+	client := api.DefaultClient
+	client.UserAgent = fmt.Sprintf("go-weather/%s (https://github.com/streek/go-weather)", appVersion)
+
+	timeout := cmd.timeout
+	if timeout == 0 && config.Timeout != "" {
+		d, err := time.ParseDuration(config.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q in config: %w", config.Timeout, err)
+		}
+		timeout = d
+	}
+	if timeout > 0 {
+		client.HTTPClient.Timeout = timeout
 	}
 
-	// Fetch and display weather information
-	return fetchWeather(latitude, longitude, cmd.showDaily, cmd.showHourly, cmd.displayMode, unitSystem, useColors)
+	if cmd.retries >= 0 {
+		client.MaxRetries = cmd.retries
+	} else if config.Retries != nil {
+		client.MaxRetries = *config.Retries
+	}
+	return nil
 }
 
 // Print detailed help information
 func printHelp() {
 	fmt.Printf("%s v%s - Command Line Weather Information\n\n", appName, appVersion)
-	fmt.Printf("Usage: %s [options]\n\n", os.Args[0])
+	fmt.Printf("Usage: %s [options]\n", os.Args[0])
+	fmt.Printf("       %s <command> [arguments]\n\n", os.Args[0])
 	fmt.Printf("Options:\n")
 	fmt.Printf("  -help, -?           Show this help message\n")
 	fmt.Printf("  -daily, -d          Show 7-day forecast\n")
 	fmt.Printf("  -hourly, -h         Show hourly forecast for the next 24 hours\n")
-	fmt.Printf("  -zip, -z [location] Override default location (ZIP code or city name)\n")
+	fmt.Printf("  -zip, -z [location] Override default location (ZIP code, city name,\n")
+	fmt.Printf("                      \"lat,lon\", geo: URI, plus code or saved name);\n")
+	fmt.Printf("                      repeat or separate with ; to compare several\n")
 	fmt.Printf("  -table, -t          Display output in table format\n")
 	fmt.Printf("  -text, -T           Display output in text format\n")
+	fmt.Printf("  -format [format]    Output format: text, table, json, csv, tsv, influx,\n")
+	fmt.Printf("                      openmetrics, or a status bar: waybar, i3blocks,\n")
+	fmt.Printf("                      i3bar, polybar, tmux\n")
+	fmt.Printf("                      (progress messages go to stderr)\n")
+	fmt.Printf("  -columns [names]    Comma-separated columns for csv and tsv output\n")
+	fmt.Printf("  -influx-url [url]   POST line protocol to an InfluxDB write endpoint\n")
+	fmt.Printf("                      (implies -format influx; token from $INFLUX_TOKEN)\n")
+	fmt.Printf("  -template [text]    Render with a Go text/template, or one named in the\n")
+	fmt.Printf("                      config's \"templates\"\n")
+	fmt.Printf("  -template-file [f]  Render with a Go text/template read from a file\n")
+	fmt.Printf("  -tui                Browse current, hourly and daily forecasts for the\n")
+	fmt.Printf("                      given and saved locations in a full-screen UI\n")
+	fmt.Printf("  -watch[=interval]   Keep running, redrawing when the data expires or\n")
+	fmt.Printf("                      every interval (e.g. -watch=10m)\n")
 	fmt.Printf("  -units, -u [system] Use specific units (metric or imperial)\n")
+	fmt.Printf("  -provider [names]   Weather data provider (%s);\n", strings.Join(forecast.ProviderNames(), ", "))
+	fmt.Printf("                      a comma-separated list is tried in order\n")
+	fmt.Printf("  -timeout [duration] Timeout for each API request (default %s)\n", api.DefaultTimeout)
+	fmt.Printf("  -retries [n]        Retries for transient API failures (default %d)\n", api.DefaultMaxRetries)
+	fmt.Printf("  -consensus          Blend all configured providers and show their spread\n")
+	fmt.Printf("  -consensus-method   Blend with median (default) or mean\n")
 	fmt.Printf("  -color, -c          Enable colored output\n")
 	fmt.Printf("  -no-color, -nc      Disable colored output\n")
 	fmt.Printf("  -save, -s           Save current settings as defaults\n\n")
 
+	fmt.Printf("Commands:\n")
+	printLocationsHelp()
+	printCacheHelp()
+	printServeHelp()
+	printPublishMQTTHelp()
+	printCheckHelp()
+	printNotifyHelp()
+
 	fmt.Printf("Examples:\n")
 	fmt.Printf("  Basic usage (shows only current weather for default location):\n")
 	fmt.Printf("    %s\n\n", os.Args[0])
@@ -266,21 +743,80 @@ func printHelp() {
 	fmt.Printf("  Show current weather in metric units without colors:\n")
 	fmt.Printf("    %s -units metric -no-color\n\n", os.Args[0])
 
+	fmt.Printf("  Use the US National Weather Service instead of Open-Meteo:\n")
+	fmt.Printf("    %s -provider nws -zip 10001\n\n", os.Args[0])
+
+	fmt.Printf("  Fall back to MET Norway and then NWS when Open-Meteo is down:\n")
+	fmt.Printf("    %s -provider open-meteo,met-norway,nws -save\n\n", os.Args[0])
+
+	fmt.Printf("  Blend every provider into a median forecast with its spread:\n")
+	fmt.Printf("    %s -consensus -daily -zip 10001\n\n", os.Args[0])
+
+	fmt.Printf("  Weather for exact coordinates or a plus code, without geocoding:\n")
+	fmt.Printf("    %s -zip 46.8523,-121.7603\n", os.Args[0])
+	fmt.Printf("    %s -zip 849VCWC8+R9\n\n", os.Args[0])
+
+	fmt.Printf("  Save a named location in imperial units and use it:\n")
+	fmt.Printf("    %s locations add -units imperial cabin \"Bend, Oregon\"\n", os.Args[0])
+	fmt.Printf("    %s -zip cabin\n\n", os.Args[0])
+
+	fmt.Printf("  Compare several places side by side, or a saved group of them:\n")
+	fmt.Printf("    %s -table -daily -zip \"home;Paris, France;Tokyo\"\n", os.Args[0])
+	fmt.Printf("    %s locations group trip \"Lisbon;Porto;Faro\"\n", os.Args[0])
+	fmt.Printf("    %s -table -zip trip\n\n", os.Args[0])
+
+	fmt.Printf("  Machine-readable forecast for scripts:\n")
+	fmt.Printf("    %s -format json -daily -zip 10001 | jq '.forecasts[0].daily[0]'\n\n", os.Args[0])
+
+	fmt.Printf("  Hourly temperatures for a spreadsheet:\n")
+	fmt.Printf("    %s -format csv -hourly -columns time,temperature -zip 10001 > hourly.csv\n\n", os.Args[0])
+
+	fmt.Printf("  A one-line summary from a template:\n")
+	fmt.Printf("    %s -template '{{icon .Current.WeatherCode}} {{temp .Current.Temperature}} {{.Location.Name}}'\n\n", os.Args[0])
+
+	fmt.Printf("  Write the hourly forecast to InfluxDB every hour:\n")
+	fmt.Printf("    %s -watch -hourly -daily -zip home -influx-url 'http://localhost:8086/api/v2/write?org=home&bucket=weather'\n\n", os.Args[0])
+
+	fmt.Printf("  Keep a daily forecast on a wall monitor, refreshed every 15 minutes:\n")
+	fmt.Printf("    %s -watch=15m -daily -table -zip home\n\n", os.Args[0])
+
+	fmt.Printf("  Weather in the tmux status line:\n")
+	fmt.Printf("    set -g status-right '#(%s -format tmux -zip home)'\n\n", os.Args[0])
+
+	fmt.Printf("  Serve forecasts as JSON to other programs on this machine:\n")
+	fmt.Printf("    %s serve -addr 127.0.0.1:8080 &\n", os.Args[0])
+	fmt.Printf("    curl 'http://127.0.0.1:8080/v1/forecast?location=home&days=3&hours=12'\n\n")
+
+	fmt.Printf("  Export weather for two sites to Prometheus on port 9811:\n")
+	fmt.Printf("    %s serve -addr :9811 -metrics \"dc1;dc2\"\n\n", os.Args[0])
+
+	fmt.Printf("  Publish home and cabin weather to Home Assistant over MQTT:\n")
+	fmt.Printf("    %s publish-mqtt -broker tcp://localhost:1883 -discovery -zip \"home;cabin\"\n\n", os.Args[0])
+
+	fmt.Printf("  Post tomorrow's frost warning for home to its chat rooms, from cron:\n")
+	fmt.Printf("    0 18 * * * %s notify -zip home -rule \"temp < 0 tomorrow\"\n\n", os.Args[0])
+
 	fmt.Printf("  Save imperial as default unit system:\n")
 	fmt.Printf("    %s -units imperial -save\n\n", os.Args[0])
 
+	fmt.Printf("Exit codes:\n")
+	fmt.Printf("  0 success, 1 other error or a check rule fired, 2 other check error,\n")
+	fmt.Printf("  3 location not found, 4 bad request, 5 rate limited, 6 weather service\n")
+	fmt.Printf("  error, 7 ambiguous location, 130 interrupted\n\n")
+
 	fmt.Printf("Configuration:\n")
 	fmt.Printf("  Your preferences are stored in: %s\n", getConfigPath())
-	fmt.Printf("  Weather data is cached for one hour in: %s\n", getCacheDir())
+	fmt.Printf("  Weather data is cached for one hour in: %s\n", cache.DefaultDir())
+	fmt.Printf("  Resolved locations are cached for 30 days in: %s\n", weather.GeocodeCacheDir())
 }
 
 // Load configuration from file
 func loadConfig() Config {
 	configPath := getConfigPath()
 	config := Config{
-		DisplayMode: DisplayText, // Default to text mode
-		Units:       UnitMetric,  // Default to metric
-		UseColors:   true,        // Default to colors enabled
+		DisplayMode: DisplayText,         // Default to text mode
+		Units:       forecast.UnitMetric, // Default to metric
+		UseColors:   true,                // Default to colors enabled
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -290,9 +826,31 @@ func loadConfig() Config {
 
 	// Try to unmarshal, ignore errors (will use default values)
 	_ = json.Unmarshal(data, &config)
+
+	// Secrets added by hand to a file saved before they were there
+	if info, err := os.Stat(configPath); err == nil && runtime.GOOS != "windows" &&
+		config.hasSecrets() && info.Mode().Perm()&0077 != 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s holds tokens or passwords but other users can read it; run chmod 600 on it\n", configPath)
+	}
 	return config
 }
 
+// hasSecrets reports whether the config holds tokens, passwords or webhook
+// URLs, which other users must not be able to read
+func (c Config) hasSecrets() bool {
+	if c.InfluxToken != "" || c.MQTT != nil && c.MQTT.Password != "" {
+		return true
+	}
+	for _, n := range c.Notify {
+		for _, t := range n.Targets {
+			if t.Token != "" || t.URL != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Save configuration to file
 func saveConfig(config Config) error {
 	configPath := getConfigPath()
@@ -309,7 +867,16 @@ func saveConfig(config Config) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	// Only the owner may read secrets. WriteFile keeps the mode of an
+	// existing file, so tighten it before writing them.
+	perm := os.FileMode(0644)
+	if config.hasSecrets() {
+		perm = 0600
+		if err := os.Chmod(configPath, perm); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.WriteFile(configPath, data, perm)
 }
 
 // getZipCode returns the location to use for weather lookup
@@ -342,516 +909,3 @@ func getConfigPath() string {
 	}
 	return filepath.Join(home, ".weather_config", configFileName)
 }
-
-// GeoLocation represents a geographical point
-type GeoLocation struct {
-	Latitude  float64
-	Longitude float64
-	Name      string
-	Country   string
-}
-
-// Use Open-Meteo's geocoding endpoint to convert location to lat/lon
-func getCoordinates(location string) (float64, float64, error) {
-	url := fmt.Sprintf("https://geocoding-api.open-meteo.com/v1/search?name=%s&count=1", location)
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, 0, err
-	}
-	defer resp.Body.Close()
-
-	type GeoResponse struct {
-		Results []struct {
-			Latitude  float64 `json:"latitude"`
-			Longitude float64 `json:"longitude"`
-			Name      string  `json:"name"`
-			Country   string  `json:"country"`
-		} `json:"results"`
-	}
-
-	var geoResp GeoResponse
-	body, _ := io.ReadAll(resp.Body)
-	err = json.Unmarshal(body, &geoResp)
-	if err != nil || len(geoResp.Results) == 0 {
-		return 0, 0, fmt.Errorf("location not found")
-	}
-
-	result := geoResp.Results[0]
-	fmt.Printf("Location detected: %s, %s\n", result.Name, result.Country)
-	return result.Latitude, result.Longitude, nil
-}
-
-// WeatherData structure to hold all weather information
-type WeatherData struct {
-	CurrentWeather struct {
-		Temperature float64 `json:"temperature"`
-		WindSpeed   float64 `json:"windspeed"`
-		WeatherCode int     `json:"weathercode"`
-		Time        string  `json:"time"`
-	} `json:"current_weather"`
-	Daily struct {
-		Time             []string  `json:"time"`
-		WeatherCode      []int     `json:"weathercode"`
-		TemperatureMax   []float64 `json:"temperature_2m_max"`
-		TemperatureMin   []float64 `json:"temperature_2m_min"`
-		PrecipitationSum []float64 `json:"precipitation_sum"`
-	} `json:"daily"`
-	Hourly struct {
-		Time          []string  `json:"time"`
-		Temperature   []float64 `json:"temperature_2m"`
-		Precipitation []float64 `json:"precipitation"`
-		WeatherCode   []int     `json:"weathercode"`
-	} `json:"hourly"`
-}
-
-// Cache file structure with timestamp and data
-type CacheFile struct {
-	Timestamp time.Time   `json:"timestamp"`
-	Data      WeatherData `json:"data"`
-}
-
-// Fetch weather data from API or cache
-func fetchWeather(lat, lon float64, showDaily, showHourly bool, displayMode DisplayMode, unitSystem UnitSystem, useColors bool) error {
-	// Check cache first
-	cacheKey := generateCacheKey(lat, lon, showDaily, showHourly, string(unitSystem))
-	cachedData, cacheExists := checkCache(cacheKey)
-	if cacheExists {
-		fmt.Println("Using cached weather data")
-		displayWeatherData(cachedData, showDaily, showHourly, displayMode, unitSystem, useColors)
-		return nil
-	}
-
-	// Build URL with parameters for requested forecast types
-	url := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current_weather=true", lat, lon)
-
-	// Add unit-specific parameters
-	if unitSystem == UnitImperial {
-		url += "&temperature_unit=fahrenheit&windspeed_unit=mph&precipitation_unit=inch"
-	}
-
-	if showDaily {
-		url += "&daily=weathercode,temperature_2m_max,temperature_2m_min,precipitation_sum"
-	}
-
-	if showHourly {
-		url += "&hourly=temperature_2m,precipitation,weathercode&forecast_hours=24"
-	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("could not read response: %w", err)
-	}
-
-	// Parse and save to cache
-	var weather WeatherData
-	err = json.Unmarshal(body, &weather)
-	if err != nil {
-		return fmt.Errorf("could not parse weather data: %w", err)
-	}
-
-	// Save to cache
-	if err := saveToCache(cacheKey, body); err != nil {
-		// Non-critical error, just log it
-		fmt.Fprintf(os.Stderr, "Warning: Failed to cache weather data: %v\n", err)
-	}
-
-	// Display the weather data
-	displayWeatherData(weather, showDaily, showHourly, displayMode, unitSystem, useColors)
-	return nil
-}
-
-// Generate a cache key from request parameters
-func generateCacheKey(lat, lon float64, daily, hourly bool, unitSystem string) string {
-	key := fmt.Sprintf("%.4f-%.4f-d%v-h%v-u%s", lat, lon, daily, hourly, unitSystem)
-	hash := md5.Sum([]byte(key))
-	return hex.EncodeToString(hash[:])
-}
-
-// Get cache directory
-func getCacheDir() string {
-	cacheDir := filepath.Join(os.TempDir(), "weather-cache")
-	os.MkdirAll(cacheDir, 0755)
-	return cacheDir
-}
-
-// Check if a valid cache exists
-func checkCache(cacheKey string) (WeatherData, bool) {
-	cacheFile := filepath.Join(getCacheDir(), cacheKey+".json")
-
-	// Check if file exists and is not too old
-	data, err := os.ReadFile(cacheFile)
-	if err != nil {
-		return WeatherData{}, false
-	}
-
-	var cache CacheFile
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return WeatherData{}, false
-	}
-
-	// Check if cache is still valid
-	if time.Since(cache.Timestamp) > cacheDuration {
-		return WeatherData{}, false
-	}
-
-	return cache.Data, true
-}
-
-// Save data to cache
-func saveToCache(cacheKey string, data []byte) error {
-	// First parse the data to verify it's valid
-	var weather WeatherData
-	if err := json.Unmarshal(data, &weather); err != nil {
-		return err
-	}
-
-	cache := CacheFile{
-		Timestamp: time.Now(),
-		Data:      weather,
-	}
-
-	cacheData, err := json.Marshal(cache)
-	if err != nil {
-		return err
-	}
-
-	cacheFile := filepath.Join(getCacheDir(), cacheKey+".json")
-	return os.WriteFile(cacheFile, cacheData, 0644)
-}
-
-// Display weather data in appropriate format
-func displayWeatherData(weather WeatherData, showDaily, showHourly bool, mode DisplayMode, unitSystem UnitSystem, useColors bool) {
-	switch mode {
-	case DisplayTable:
-		displayWeatherAsTable(weather, showDaily, showHourly, unitSystem, useColors)
-	default:
-		displayWeatherAsText(weather, showDaily, showHourly, unitSystem, useColors)
-	}
-}
-
-// Get the appropriate temperature units based on unit system
-func getTempUnit(unitSystem UnitSystem) string {
-	if unitSystem == UnitImperial {
-		return "°F"
-	}
-	return "°C"
-}
-
-// Get the appropriate wind speed units based on unit system
-func getWindUnit(unitSystem UnitSystem) string {
-	if unitSystem == UnitImperial {
-		return "mph"
-	}
-	return "km/h"
-}
-
-// Get the appropriate precipitation units based on unit system
-func getPrecipUnit(unitSystem UnitSystem) string {
-	if unitSystem == UnitImperial {
-		return "in"
-	}
-	return "mm"
-}
-
-// Helper function to get unit system name
-func getUnitSystemName(unit UnitSystem) string {
-	switch unit {
-	case UnitMetric:
-		return "Metric (°C, km/h, mm)"
-	case UnitImperial:
-		return "Imperial (°F, mph, in)"
-	default:
-		return string(unit)
-	}
-}
-
-// colorizeTemp applies color to temperature based on its value
-func colorizeTemp(temp float64, unitSystem UnitSystem) string {
-	// Convert to Celsius for standard comparison if needed
-	tempC := temp
-	if unitSystem == UnitImperial {
-		tempC = (temp - 32) * 5 / 9
-	}
-
-	// Color based on temperature ranges (in Celsius)
-	var colorCode string
-	switch {
-	case tempC < -10:
-		colorCode = colorBlue // Very cold
-	case tempC < 0:
-		colorCode = colorCyan // Cold
-	case tempC < 15:
-		colorCode = colorWhite // Cool
-	case tempC < 25:
-		colorCode = colorGreen // Pleasant
-	case tempC < 30:
-		colorCode = colorYellow // Warm
-	case tempC < 35:
-		colorCode = colorMagenta // Hot
-	default:
-		colorCode = colorRed // Very hot
-	}
-
-	// Format with units
-	unit := getTempUnit(unitSystem)
-	return fmt.Sprintf("%s%.1f%s%s", colorCode, temp, unit, colorReset)
-}
-
-// Text-based display format
-func displayWeatherAsText(weather WeatherData, showDaily, showHourly bool, unitSystem UnitSystem, useColors bool) {
-	tempUnit := getTempUnit(unitSystem)
-	windUnit := getWindUnit(unitSystem)
-	precipUnit := getPrecipUnit(unitSystem)
-
-	fmt.Println("Current Weather:")
-	if useColors {
-		fmt.Printf("  Temperature: %s\n", colorizeTemp(weather.CurrentWeather.Temperature, unitSystem))
-	} else {
-		fmt.Printf("  Temperature: %.1f%s\n", weather.CurrentWeather.Temperature, tempUnit)
-	}
-
-	// Add high/low temperatures for today if daily data is available
-	if len(weather.Daily.Time) > 0 {
-		today := time.Now().Format("2006-01-02")
-		for i, day := range weather.Daily.Time {
-			if day == today {
-				if useColors {
-					fmt.Printf("  High/Low: %s/%s\n",
-						colorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
-						colorizeTemp(weather.Daily.TemperatureMin[i], unitSystem))
-				} else {
-					fmt.Printf("  High/Low: %.1f%s/%.1f%s\n",
-						weather.Daily.TemperatureMax[i], tempUnit,
-						weather.Daily.TemperatureMin[i], tempUnit)
-				}
-				break
-			}
-		}
-	}
-
-	fmt.Printf("  Wind Speed: %.1f %s\n", weather.CurrentWeather.WindSpeed, windUnit)
-	fmt.Printf("  Time: %s\n", formatTime(weather.CurrentWeather.Time))
-	fmt.Printf("  Weather: %s\n", getWeatherDescription(weather.CurrentWeather.WeatherCode))
-
-	// Display daily forecast if requested
-	if showDaily && len(weather.Daily.Time) > 0 {
-		fmt.Println("\n7-Day Forecast:")
-		for i, day := range weather.Daily.Time {
-			t, _ := time.Parse("2006-01-02", day)
-
-			if useColors {
-				fmt.Printf("  %s: %s, %s to %s, Precipitation: %.1f%s\n",
-					t.Format("Mon Jan 2"),
-					getWeatherDescription(weather.Daily.WeatherCode[i]),
-					colorizeTemp(weather.Daily.TemperatureMin[i], unitSystem),
-					colorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
-					weather.Daily.PrecipitationSum[i],
-					precipUnit)
-			} else {
-				fmt.Printf("  %s: %s, %.1f%s to %.1f%s, Precipitation: %.1f%s\n",
-					t.Format("Mon Jan 2"),
-					getWeatherDescription(weather.Daily.WeatherCode[i]),
-					weather.Daily.TemperatureMin[i], tempUnit,
-					weather.Daily.TemperatureMax[i], tempUnit,
-					weather.Daily.PrecipitationSum[i], precipUnit)
-			}
-		}
-	}
-
-	// Display hourly forecast if requested
-	if showHourly && len(weather.Hourly.Time) > 0 {
-		fmt.Println("\nHourly Forecast (next 24h):")
-		for i := 0; i < 24 && i < len(weather.Hourly.Time); i++ {
-			t, _ := time.Parse("2006-01-02T15:04", weather.Hourly.Time[i])
-
-			if useColors {
-				fmt.Printf("  %s: %s, %s, Precipitation: %.1f%s\n",
-					t.Format("15:04"),
-					getWeatherDescription(weather.Hourly.WeatherCode[i]),
-					colorizeTemp(weather.Hourly.Temperature[i], unitSystem),
-					weather.Hourly.Precipitation[i], precipUnit)
-			} else {
-				fmt.Printf("  %s: %s, %.1f%s, Precipitation: %.1f%s\n",
-					t.Format("15:04"),
-					getWeatherDescription(weather.Hourly.WeatherCode[i]),
-					weather.Hourly.Temperature[i], tempUnit,
-					weather.Hourly.Precipitation[i], precipUnit)
-			}
-		}
-	}
-}
-
-// Table-based display format
-func displayWeatherAsTable(weather WeatherData, showDaily, showHourly bool, unitSystem UnitSystem, useColors bool) {
-	tempUnit := getTempUnit(unitSystem)
-	windUnit := getWindUnit(unitSystem)
-	precipUnit := getPrecipUnit(unitSystem)
-
-	// Current weather display
-	fmt.Println("Current Weather:")
-	printLine(60) // Increased width to accommodate high/low
-	fmt.Printf("| %-10s | %-12s | %-10s | %-12s | %-15s |\n", "Temperature", "High/Low", "Wind", "Time", "Condition")
-	printLine(60)
-
-	// Find today's high/low if available
-	highTemp, lowTemp := weather.CurrentWeather.Temperature, weather.CurrentWeather.Temperature
-	if len(weather.Daily.Time) > 0 {
-		today := time.Now().Format("2006-01-02")
-		for i, day := range weather.Daily.Time {
-			if day == today {
-				highTemp = weather.Daily.TemperatureMax[i]
-				lowTemp = weather.Daily.TemperatureMin[i]
-				break
-			}
-		}
-	}
-
-	if useColors {
-		fmt.Printf("| %-10s | %-12s | %-10.1f %s | %-12s | %-15s |\n",
-			colorizeTemp(weather.CurrentWeather.Temperature, unitSystem),
-			fmt.Sprintf("%s/%s",
-				colorizeTemp(highTemp, unitSystem),
-				colorizeTemp(lowTemp, unitSystem)),
-			weather.CurrentWeather.WindSpeed, windUnit,
-			formatTime(weather.CurrentWeather.Time),
-			truncateString(getWeatherDescription(weather.CurrentWeather.WeatherCode), 15))
-	} else {
-		fmt.Printf("| %-10.1f%s | %-12s | %-10.1f %s | %-12s | %-15s |\n",
-			weather.CurrentWeather.Temperature, tempUnit,
-			fmt.Sprintf("%.1f/%.1f%s", highTemp, lowTemp, tempUnit),
-			weather.CurrentWeather.WindSpeed, windUnit,
-			formatTime(weather.CurrentWeather.Time),
-			truncateString(getWeatherDescription(weather.CurrentWeather.WeatherCode), 15))
-	}
-	printLine(60)
-
-	// Display daily forecast if requested
-	if showDaily && len(weather.Daily.Time) > 0 {
-		fmt.Println("\n7-Day Forecast:")
-		printLine(80)
-		fmt.Printf("| %-10s | %-15s | %-12s | %-12s | %-15s |\n",
-			"Date", "Condition", "Min Temp", "Max Temp", "Precipitation")
-		printLine(80)
-
-		for i, day := range weather.Daily.Time {
-			t, _ := time.Parse("2006-01-02", day)
-
-			if useColors {
-				fmt.Printf("| %-10s | %-15s | %-12s | %-12s | %-15.1f%s |\n",
-					t.Format("Mon Jan 2"),
-					truncateString(getWeatherDescription(weather.Daily.WeatherCode[i]), 15),
-					colorizeTemp(weather.Daily.TemperatureMin[i], unitSystem),
-					colorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
-					weather.Daily.PrecipitationSum[i], precipUnit)
-			} else {
-				fmt.Printf("| %-10s | %-15s | %-12.1f%s | %-12.1f%s | %-15.1f%s |\n",
-					t.Format("Mon Jan 2"),
-					truncateString(getWeatherDescription(weather.Daily.WeatherCode[i]), 15),
-					weather.Daily.TemperatureMin[i], tempUnit,
-					weather.Daily.TemperatureMax[i], tempUnit,
-					weather.Daily.PrecipitationSum[i], precipUnit)
-			}
-		}
-		printLine(80)
-	}
-
-	// Display hourly forecast if requested
-	if showHourly && len(weather.Hourly.Time) > 0 {
-		fmt.Println("\nHourly Forecast (next 24h):")
-		printLine(60)
-		fmt.Printf("| %-5s | %-15s | %-12s | %-15s |\n",
-			"Time", "Condition", "Temperature", "Precipitation")
-		printLine(60)
-
-		for i := 0; i < 24 && i < len(weather.Hourly.Time); i++ {
-			t, _ := time.Parse("2006-01-02T15:04", weather.Hourly.Time[i])
-
-			if useColors {
-				fmt.Printf("| %-5s | %-15s | %-12s | %-15.1f%s |\n",
-					t.Format("15:04"),
-					truncateString(getWeatherDescription(weather.Hourly.WeatherCode[i]), 15),
-					colorizeTemp(weather.Hourly.Temperature[i], unitSystem),
-					weather.Hourly.Precipitation[i], precipUnit)
-			} else {
-				fmt.Printf("| %-5s | %-15s | %-12.1f%s | %-15.1f%s |\n",
-					t.Format("15:04"),
-					truncateString(getWeatherDescription(weather.Hourly.WeatherCode[i]), 15),
-					weather.Hourly.Temperature[i], tempUnit,
-					weather.Hourly.Precipitation[i], precipUnit)
-			}
-		}
-		printLine(60)
-	}
-}
-
-// Helper function to print a horizontal line for tables
-func printLine(width int) {
-	fmt.Print("+")
-	for i := 0; i < width-2; i++ {
-		fmt.Print("-")
-	}
-	fmt.Println("+")
-}
-
-// Helper function to truncate strings to fit in table cells
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
-
-// Helper function to format ISO time string
-func formatTime(timeStr string) string {
-	// Parse time and format it to a more readable form
-	t, err := time.Parse("2006-01-02T15:04", timeStr)
-	if err != nil {
-		return timeStr
-	}
-	return t.Format("15:04")
-}
-
-// getWeatherDescription converts weather code to human-readable description
-func getWeatherDescription(code int) string {
-	descriptions := map[int]string{
-		0:  "Clear sky",
-		1:  "Mainly clear",
-		2:  "Partly cloudy",
-		3:  "Overcast",
-		45: "Fog",
-		48: "Depositing rime fog",
-		51: "Light drizzle",
-		53: "Moderate drizzle",
-		55: "Dense drizzle",
-		56: "Light freezing drizzle",
-		57: "Dense freezing drizzle",
-		61: "Slight rain",
-		63: "Moderate rain",
-		65: "Heavy rain",
-		66: "Light freezing rain",
-		67: "Heavy freezing rain",
-		71: "Slight snow fall",
-		73: "Moderate snow fall",
-		75: "Heavy snow fall",
-		77: "Snow grains",
-		80: "Slight rain showers",
-		81: "Moderate rain showers",
-		82: "Violent rain showers",
-		85: "Slight snow showers",
-		86: "Heavy snow showers",
-		95: "Thunderstorm",
-		96: "Thunderstorm with slight hail",
-		99: "Thunderstorm with heavy hail",
-	}
-
-	if desc, ok := descriptions[code]; ok {
-		return desc
-	}
-	return "Unknown"
-}
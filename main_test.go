@@ -1,36 +1,613 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/streek/go-weather/alert"
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/notify"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
 )
 
-func TestGetTempUnit(t *testing.T) {
+func TestPickLocation(t *testing.T) {
+	ambiguous := &geocode.AmbiguousError{
+		Query: "Springfield",
+		Candidates: []geocode.GeoLocation{
+			{Name: "Springfield", Admin1: "Missouri", Country: "United States"},
+			{Name: "Springfield", Admin1: "Illinois", Country: "United States"},
+		},
+	}
+
+	var out strings.Builder
+	// An invalid answer is asked again
+	loc, err := pickLocation(strings.NewReader("7\n2\n"), &out, ambiguous)
+	if err != nil {
+		t.Fatalf("pickLocation: %v", err)
+	}
+	if loc.Admin1 != "Illinois" {
+		t.Errorf("picked %s; want Illinois", loc)
+	}
+	if !strings.Contains(out.String(), "2. Springfield, Illinois, United States") {
+		t.Errorf("picker output missing candidate list:\n%s", out.String())
+	}
+
+	if _, err := pickLocation(strings.NewReader(""), &out, ambiguous); err == nil {
+		t.Error("pickLocation without input succeeded")
+	}
+}
+
+func TestLocationName(t *testing.T) {
+	tests := map[string]bool{
+		"home":         true,
+		" Work ":       true,
+		"":             false,
+		"my cabin":     false,
+		"paris,france": false,
+		"a;b":          false,
+		"-z":           false,
+		"40.7,-74.0":   false,
+	}
+	for name, valid := range tests {
+		if _, err := locationName(name); (err == nil) != valid {
+			t.Errorf("locationName(%q) error = %v; want valid %v", name, err, valid)
+		}
+	}
+}
+
+func TestLocationBook(t *testing.T) {
+	tempConfig(t)
+	ctx := context.Background()
+	config := loadConfig()
+
+	// Coordinates need no geocoding request
+	if err := addLocation(ctx, &config, "Home", "40.7128,-74.0060", forecast.UnitImperial); err != nil {
+		t.Fatal(err)
+	}
+	if err := addLocation(ctx, &config, "work", "geo:51.5074,-0.1278", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := addLocation(ctx, &config, "my cabin", "44.0,-121.3", ""); err == nil {
+		t.Error("add with an invalid name succeeded")
+	}
+	reloaded := loadConfig()
+	saved, ok := reloaded.savedLocation("HOME")
+	if !ok || saved.Location.Latitude != 40.7128 || saved.Units != forecast.UnitImperial {
+		t.Fatalf("saved home = %+v, %v", saved, ok)
+	}
+
+	if err := renameLocation(&config, "home", "work"); err == nil {
+		t.Error("rename onto an existing name succeeded")
+	}
+	if err := renameLocation(&config, "Home", "cabin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := renameLocation(&config, "home", "house"); err == nil {
+		t.Error("rename of a missing name succeeded")
+	}
+
+	var out strings.Builder
+	listLocations(&out, loadConfig())
+	want := fmt.Sprintf("%-12s %s\n%-12s units: Imperial", "cabin", saved.Location.Summary(), "")
+	if !strings.HasPrefix(out.String(), want) || !strings.Contains(out.String(), "\nwork ") || strings.Contains(out.String(), "home") {
+		t.Errorf("list =\n%s\nwant cabin with imperial units, then work", out.String())
+	}
+
+	if err := runLocations(ctx, []string{"remove", "Cabin"}); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded = loadConfig(); len(reloaded.Locations) != 1 {
+		t.Errorf("locations after remove = %v; want only work", reloaded.Locations)
+	}
+	if err := runLocations(ctx, []string{"remove", "cabin"}); err == nil {
+		t.Error("removing a missing name succeeded")
+	}
+}
+
+// recordingProvider returns an empty forecast, noting the coordinates asked for
+type recordingProvider struct {
+	lat, lon float64
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) Fetch(ctx context.Context, lat, lon float64, opts forecast.Options) (forecast.WeatherData, error) {
+	p.lat, p.lon = lat, lon
+	return forecast.WeatherData{}, nil
+}
+
+func TestSavedLocationSkipsGeocoder(t *testing.T) {
+	geocoder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("geocoder called for %s", r.URL)
+		http.Error(w, "unexpected", http.StatusInternalServerError)
+	}))
+	defer geocoder.Close()
+
+	home := geocode.GeoLocation{Name: "Bend", Latitude: 44.0582, Longitude: -121.3153, Timezone: "America/Los_Angeles"}
+	config := Config{Locations: map[string]SavedLocation{"home": {Location: home}}}
+	provider := &recordingProvider{}
+	client := &weather.Client{
+		Geocoder: &geocode.Geocoder{BaseURL: geocoder.URL, HTTP: api.NewClient()},
+		Provider: provider,
+	}
+
+	var flags locationList
+	flags.Set("Home")
+	sections, err := fetchSections(context.Background(), client, &config, config.expandLocations(flags), forecast.Options{}, io.Discard, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sections[0].Location.Name != "Bend" || provider.lat != home.Latitude || provider.lon != home.Longitude {
+		t.Errorf("-z home = %+v at %v, %v; want the saved location", sections[0].Location, provider.lat, provider.lon)
+	}
+}
+
+func TestExpandLocations(t *testing.T) {
+	config := Config{Groups: map[string][]string{"trip": {"Lisbon", "Porto"}}}
+
+	var flags locationList
+	flags.Set("home; Paris, France;")
+	flags.Set("Trip")
+
+	got := config.expandLocations(flags)
+	want := []string{"home", "Paris, France", "Lisbon", "Porto"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expandLocations = %q; want %q", got, want)
+	}
+}
+
+func TestProviderList(t *testing.T) {
+	got := providerList(" met-norway, ,nws,")
+	want := []string{"met-norway", "nws"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("providerList = %q; want %q", got, want)
+	}
+
+	cmd := &Command{provider: "met-norway, nws,"}
+	provider, err := cmd.newProvider(Config{})
+	if err != nil || provider.Name() != "met-norway,nws" {
+		t.Errorf("newProvider = %v, %v; want the met-norway,nws chain", provider, err)
+	}
+}
+
+func TestWatchFlag(t *testing.T) {
 	tests := []struct {
-		name       string
-		unitSystem UnitSystem
-		want       string
+		value    string
+		interval time.Duration
+		ok       bool
 	}{
-		{"metric", UnitMetric, "°C"},
-		{"imperial", UnitImperial, "°F"},
-		{"empty", "", "°C"},
+		{"true", 0, true},
+		{"15m", 15 * time.Minute, true},
+		{"10s", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		var w watchFlag
+		err := w.Set(tt.value)
+		if (err == nil) != tt.ok || w.interval != tt.interval || (tt.ok && !w.enabled) {
+			t.Errorf("Set(%q) = %+v, %v; want interval %s, ok %v", tt.value, w, err, tt.interval, tt.ok)
+		}
 	}
+}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			got := getTempUnit(tc.unitSystem)
-			if got != tc.want {
-				t.Errorf("getTempUnit(%s) = %s; want %s", tc.unitSystem, got, tc.want)
+func TestParseFlagsWatch(t *testing.T) {
+	tests := []struct {
+		args     []string
+		interval time.Duration
+		err      string
+	}{
+		{[]string{"-watch"}, 0, ""},
+		{[]string{"-watch=10m", "-d"}, 10 * time.Minute, ""},
+		{[]string{"-watch", "10m"}, 0, "-watch=10m"},
+		{[]string{"-d", "Paris"}, 0, `unexpected argument "Paris"`},
+	}
+	for _, tt := range tests {
+		cmd, err := parseFlags(tt.args)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseFlags(%q) error = %v; want %q", tt.args, err, tt.err)
 			}
-		})
+			continue
+		}
+		if err != nil || !cmd.watch.enabled || cmd.watch.interval != tt.interval {
+			t.Errorf("parseFlags(%q) = %+v, %v; want interval %s", tt.args, cmd.watch, err, tt.interval)
+		}
+	}
+}
+
+func TestWatcherShowsStaleData(t *testing.T) {
+	var out strings.Builder
+	w := &watcher{
+		out: &out,
+		draw: func(w io.Writer, sections []render.Section) error {
+			fmt.Fprintf(w, "%d sections\n", len(sections))
+			return nil
+		},
+	}
+	updated := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	next := updated.Add(2 * time.Hour)
+	if err := w.show([]render.Section{{}}, updated, next, errors.New("connection refused")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1 sections", "connection refused", "STALE: showing data from 09:00:00", "retrying at 11:00:00"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWatcherBareForStatusBars(t *testing.T) {
+	var out, log strings.Builder
+	w := &watcher{
+		out:  &out,
+		bare: true,
+		log:  &log,
+		draw: func(w io.Writer, sections []render.Section) error {
+			fmt.Fprintf(w, "%d sections\n", len(sections))
+			return nil
+		},
+	}
+	updated := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	next := updated.Add(2 * time.Hour)
+	if err := w.show([]render.Section{{}}, updated, next, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.show([]render.Section{{}}, updated, next, errors.New("connection refused")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "1 sections\n" {
+		t.Errorf("output = %q; want only the first report", out.String())
+	}
+	if !strings.Contains(log.String(), "connection refused") {
+		t.Errorf("log = %q; want the fetch error", log.String())
+	}
+}
+
+func TestWatcherStopsOnAmbiguousLocation(t *testing.T) {
+	ambiguous := &geocode.AmbiguousError{Query: "Springfield", Candidates: []geocode.GeoLocation{{Name: "Springfield"}, {Name: "Springfield"}}}
+	w := &watcher{
+		out: io.Discard,
+		fetch: func(ctx context.Context) ([]render.Section, error) {
+			return nil, fmt.Errorf("could not get coordinates for Springfield: %w", ambiguous)
+		},
+		draw: func(w io.Writer, sections []render.Section) error { return nil },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.run(ctx); exitCode(err) != exitAmbiguous {
+		t.Errorf("run = %v; want the ambiguous location (exit %d)", err, exitAmbiguous)
+	}
+}
+
+func TestInfluxWriterRedactsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	client := api.NewClient()
+	client.MaxRetries = 0
+	iw := &influxWriter{url: strings.Replace(server.URL, "//", "//admin:hunter2@", 1) + "/write?db=weather&p=hunter2", http: client}
+
+	var section render.Section
+	section.Weather.CurrentWeather.Time = "2024-05-01T12:00"
+	var out strings.Builder
+	if err := iw.write(context.Background(), &out, []render.Section{section}, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	err := iw.write(context.Background(), io.Discard, []render.Section{section}, render.Options{})
+	if err == nil {
+		t.Fatal("write to a closed server succeeded")
+	}
+	for _, msg := range []string{out.String(), err.Error()} {
+		if strings.Contains(msg, "hunter2") || strings.Contains(msg, "admin") || !strings.Contains(msg, server.URL+"/write") {
+			t.Errorf("message = %q; want the URL without credentials", msg)
+		}
+	}
+}
+
+func TestInfluxWriter(t *testing.T) {
+	var got struct {
+		auth, contentType, body string
+	}
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got.auth, got.contentType, got.body = r.Header.Get("Authorization"), r.Header.Get("Content-Type"), string(body)
+		w.WriteHeader(status)
+		if status != http.StatusNoContent {
+			w.Write([]byte(`{"code":"invalid","message":"unable to parse points"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient()
+	client.MaxRetries = 0
+	iw := &influxWriter{url: server.URL + "/api/v2/write?bucket=weather", token: "secret", http: client}
+
+	var section render.Section
+	section.Label = "home"
+	section.Weather.CurrentWeather.Time = "2024-05-01T12:00"
+	var out strings.Builder
+	if err := iw.write(context.Background(), &out, []render.Section{section}, render.Options{Units: forecast.UnitMetric}); err != nil {
+		t.Fatal(err)
+	}
+	if got.auth != "Token secret" || !strings.HasPrefix(got.contentType, "text/plain") {
+		t.Errorf("headers = %q, %q", got.auth, got.contentType)
+	}
+	if !strings.HasPrefix(got.body, "weather_current,location=home,units=metric ") {
+		t.Errorf("body = %q", got.body)
+	}
+	if !strings.Contains(out.String(), "Wrote 1 points") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	status = http.StatusBadRequest
+	err := iw.write(context.Background(), io.Discard, []render.Section{section}, render.Options{})
+	if !errors.Is(err, api.ErrBadRequest) || !strings.Contains(err.Error(), "unable to parse points") {
+		t.Errorf("write error = %v; want the rejection reason", err)
 	}
 }
 
-func TestColorizeTemp(t *testing.T) {
-	// Simple test just to check it doesn't crash
-	result := colorizeTemp(20.0, UnitMetric)
-	if result == "" {
-		t.Error("colorizeTemp returned empty string")
+func TestMQTTMessages(t *testing.T) {
+	var section render.Section
+	section.Label = "New York, NY"
+	section.Weather.Source = "open-meteo"
+	section.Weather.CurrentWeather.Time = "2024-05-01T12:00"
+	section.Weather.CurrentWeather.Temperature = 17.5
+	section.Weather.Daily.Time = []string{"2024-05-01"}
+	section.Weather.Daily.WeatherCode = []int{61}
+	section.Weather.Daily.TemperatureMax = []float64{20}
+	section.Weather.Daily.TemperatureMin = []float64{9}
+	section.Weather.Daily.PrecipitationSum = []float64{2.5}
+
+	messages := mqttMessages(MQTTConfig{Discovery: true}, []render.Section{section}, forecast.UnitMetric)
+	got := map[string]string{}
+	for _, m := range messages {
+		got[m.topic] = string(m.payload)
+	}
+
+	for topic, want := range map[string]string{
+		"weather/new_york_ny/temperature":       "17.5",
+		"weather/new_york_ny/precipitation_sum": "2.5",
+		"weather/new_york_ny/observed":          "2024-05-01T12:00:00Z",
+	} {
+		if got[topic] != want {
+			t.Errorf("%s = %q; want %q", topic, got[topic], want)
+		}
+	}
+	if !strings.Contains(got["weather/new_york_ny"], `"query":"New York, NY"`) {
+		t.Errorf("state JSON = %s", got["weather/new_york_ny"])
+	}
+
+	discovery := got["homeassistant/sensor/go_weather_new_york_ny/temperature/config"]
+	for _, want := range []string{`"state_topic":"weather/new_york_ny/temperature"`, `"unit_of_measurement":"°C"`, `"device_class":"temperature"`} {
+		if !strings.Contains(discovery, want) {
+			t.Errorf("discovery config missing %s: %s", want, discovery)
+		}
 	}
 }
 
-// Add more tests for other key functions
+func TestDescribeResult(t *testing.T) {
+	var section render.Section
+	section.Label = "site"
+	section.Location.Timezone = "America/New_York"
+	section.Weather.Hourly.Time = []string{"2024-05-01T14:00", "2024-05-01T15:00"}
+	section.Weather.Hourly.Temperature = []float64{10, 11}
+	section.Weather.Hourly.Precipitation = []float64{1.5, 1}
+	section.Weather.Hourly.WeatherCode = []int{61, 95}
+
+	var rules []alert.Rule
+	for _, text := range []string{"precip > 2mm within 6h", "code in snow within 6h"} {
+		rule, err := alert.Parse(text, forecast.UnitMetric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+	results := evalRules(rules, []render.Section{section}, time.Date(2024, 5, 1, 14, 10, 0, 0, time.UTC))
+
+	want := []string{
+		"FIRED precip > 2mm within 6h [site]: 2.5mm by Wed 11:00",
+		"ok    code in snow within 6h [site]: Slight rain (61) at Wed 10:00",
+	}
+	for i, r := range results {
+		if got := describeResult(r, true, forecast.UnitMetric); got != want[i] {
+			t.Errorf("describeResult = %q; want %q", got, want[i])
+		}
+	}
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{runCheck(context.Background(), []string{"bogus"}), exitCheckError},
+		{runCheck(context.Background(), nil), exitCheckError},
+		{exitStatus(exitRuleFired), exitRuleFired},
+		{fmt.Errorf("check: %w", geocode.ErrNotFound), exitNotFound},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.code {
+			t.Errorf("exitCode(%v) = %d; want %d", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestNotifyMessages(t *testing.T) {
+	var section render.Section
+	section.Label = "site"
+	section.Location.Name = "Albany"
+	section.Location.Timezone = "America/New_York"
+	section.Weather.CurrentWeather.Time = "2024-05-01T14:00"
+	section.Weather.CurrentWeather.Temperature = 12.5
+	section.Weather.CurrentWeather.WindSpeed = 18
+	section.Weather.CurrentWeather.WeatherCode = 3
+	section.Weather.Daily.Time = []string{"2024-04-30", "2024-05-01", "2024-05-02", "2024-05-03", "2024-05-04"}
+	section.Weather.Daily.TemperatureMin = []float64{1, 4, -2, 5, 6}
+	section.Weather.Daily.TemperatureMax = []float64{9, 16, 8, 14, 15}
+	section.Weather.Daily.PrecipitationSum = []float64{0, 2.5, 0, 0, 1}
+	section.Weather.Daily.WeatherCode = []int{0, 61, 3, 1, 80}
+	now := time.Date(2024, 5, 1, 14, 10, 0, 0, time.UTC)
+
+	got := summaryMessage(section, forecast.UnitMetric, now).Text()
+	want := "Weather for Albany\n" +
+		"Now: 12.5°C, Overcast, wind 18 km/h\n" +
+		"Today: Slight rain, 4–16°C, 2.5mm precipitation\n" +
+		"Tomorrow: Overcast, -2–8°C, 0.0mm precipitation\n" +
+		"Fri May 3: Mainly clear, 5–14°C, 0.0mm precipitation"
+	if got != want {
+		t.Errorf("summary =\n%s\nwant\n%s", got, want)
+	}
+
+	var rules []alert.Rule
+	for _, text := range []string{"temp < 0 tomorrow", "wind > 40"} {
+		rule, err := alert.Parse(text, forecast.UnitMetric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+	msg, fired, _ := alertMessage(section, rules, forecast.UnitMetric, now)
+	if fired != "temp < 0 tomorrow" || msg.Text() != "Weather alert for Albany\ntemp < 0 tomorrow: -2.0°C on Thu May 2" {
+		t.Errorf("alert = %q, fired %q", msg.Text(), fired)
+	}
+	if _, fired, _ := alertMessage(section, rules[1:], forecast.UnitMetric, now); fired != "" {
+		t.Errorf("fired = %q; want none", fired)
+	}
+
+	section.Weather.Daily.PrecipitationSum = []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+	rule, _ := alert.Parse("precip > 2 tomorrow", forecast.UnitMetric)
+	if _, fired, empty := alertMessage(section, []alert.Rule{rule}, forecast.UnitMetric, now); fired != "" || len(empty) != 1 {
+		t.Errorf("missing precipitation: fired %q, empty %q; want no data", fired, empty)
+	}
+}
+
+// fakeNotifier counts messages, failing while fail is set
+type fakeNotifier struct {
+	sent int
+	fail bool
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	if f.fail {
+		return errors.New("connection reset")
+	}
+	f.sent++
+	return nil
+}
+
+func TestNotifyDeliverPerTarget(t *testing.T) {
+	slack, discord := &fakeNotifier{}, &fakeNotifier{fail: true}
+	job := &notifyJob{targets: []*notifyState{{notifier: slack, kind: "slack"}, {notifier: discord, kind: "discord"}}}
+	msg := notify.Message{Title: "Weather alert for Albany"}
+
+	kinds, errs := job.deliver(context.Background(), msg, "wind > 40", true)
+	if len(kinds) != 1 || kinds[0] != "slack" || len(errs) != 1 {
+		t.Fatalf("first delivery = %q, %v; want slack and one error", kinds, errs)
+	}
+	// Only the target that failed gets the same alert again
+	discord.fail = false
+	kinds, errs = job.deliver(context.Background(), msg, "wind > 40", true)
+	if len(kinds) != 1 || kinds[0] != "discord" || len(errs) != 0 || slack.sent != 1 || discord.sent != 1 {
+		t.Errorf("second delivery = %q, %v; sent slack %d, discord %d; want discord only", kinds, errs, slack.sent, discord.sent)
+	}
+	if kinds, _ := job.deliver(context.Background(), msg, "wind > 40", true); len(kinds) != 0 {
+		t.Errorf("unchanged alert posted to %q", kinds)
+	}
+}
+
+// setenv sets an environment variable until the test ends
+func setenv(t *testing.T, key, value string) {
+	old, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// tempConfig points the config file and the caches at fresh temporary
+// directories
+func tempConfig(t *testing.T) {
+	setenv(t, "HOME", t.TempDir())
+	setenv(t, "TMPDIR", t.TempDir())
+}
+
+func TestSaveConfigSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix file modes")
+	}
+	tempConfig(t)
+	// A config saved before it held secrets
+	config := loadConfig()
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(getConfigPath(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Notify = map[string]NotifyConfig{"home": {Targets: []NotifyTarget{{Type: "slack", URL: "https://hooks.slack.com/services/T/B/X"}}}}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("mode with a webhook URL = %o; want 600", got)
+	}
+}
+
+func TestCacheCommands(t *testing.T) {
+	tempConfig(t)
+	config := loadConfig()
+	config.Choices = map[string]geocode.GeoLocation{geocode.NormalizeQuery("Springfield"): {Name: "Springfield", Admin1: "Illinois"}}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(config)
+	metNorway := cache.New(forecast.METNorwayCacheDir(), time.Hour)
+	for _, c := range []*cache.Cache{client.Cache, metNorway, client.GeoCache} {
+		if err := c.Put("entry", "data"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runCache(context.Background(), []string{"clear", "forecast"}); err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	if client.Cache.Get("entry", &v) || metNorway.Get("entry", &v) {
+		t.Error("forecast entries survived cache clear forecast")
+	}
+	if !client.GeoCache.Get("entry", &v) {
+		t.Error("cache clear forecast removed a location")
+	}
+
+	if err := runCache(context.Background(), []string{"forget", "springfield"}); err != nil {
+		t.Fatal(err)
+	}
+	if choices := loadConfig().Choices; len(choices) != 0 {
+		t.Errorf("choices after forget = %v; want none", choices)
+	}
+}
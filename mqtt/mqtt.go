@@ -0,0 +1,277 @@
+// Package mqtt is a minimal MQTT 3.1.1 client that publishes messages at
+// QoS 1. It covers what a periodic publisher needs: connecting with
+// credentials over TCP or TLS, publishing retained messages and waiting for
+// the broker to acknowledge each one.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Defaults for Options
+const (
+	DefaultPort    = "1883"
+	DefaultTLSPort = "8883"
+	DefaultTimeout = 10 * time.Second
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetDisconnect = 14
+)
+
+// Options configures a connection
+type Options struct {
+	Broker   string // tcp://host:port, mqtt://, or ssl://, tls://, mqtts:// for TLS
+	ClientID string
+	Username string
+	Password string
+	Timeout  time.Duration // for connecting and each acknowledgement; 0 uses DefaultTimeout
+}
+
+// ConnectError is a connection refused by the broker
+type ConnectError byte
+
+func (e ConnectError) Error() string {
+	reasons := map[ConnectError]string{
+		1: "unacceptable protocol version",
+		2: "client identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+	if reason, ok := reasons[e]; ok {
+		return "connection refused: " + reason
+	}
+	return fmt.Sprintf("connection refused: code %d", byte(e))
+}
+
+// Client is a connection to a broker. It is not safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+	nextID  uint16
+}
+
+// Dial connects to the broker and completes the MQTT handshake
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	addr, useTLS, err := brokerAddress(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to MQTT broker: %w", err)
+	}
+
+	c := &Client{conn: conn, r: bufio.NewReader(conn), timeout: timeout}
+	if err := c.connect(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// brokerAddress parses a broker URL into host:port and whether to use TLS.
+// A bare host:port is accepted as plain TCP.
+func brokerAddress(broker string) (string, bool, error) {
+	if broker == "" {
+		return "", false, errors.New("no MQTT broker configured")
+	}
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("tcp://" + broker)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid MQTT broker %q: %w", broker, err)
+		}
+	}
+
+	var useTLS bool
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+	default:
+		return "", false, fmt.Errorf("unsupported MQTT broker scheme %q (use tcp or ssl)", u.Scheme)
+	}
+	port := u.Port()
+	if port == "" {
+		port = DefaultPort
+		if useTLS {
+			port = DefaultTLSPort
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// connect sends CONNECT with a clean session and waits for CONNACK
+func (c *Client) connect(opts Options) error {
+	var flags byte = 0x02 // clean session
+	payload := appendString(nil, opts.ClientID)
+	if opts.Username != "" {
+		flags |= 0x80
+		payload = appendString(payload, opts.Username)
+		if opts.Password != "" {
+			flags |= 0x40
+			payload = appendString(payload, opts.Password)
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 3.1.1
+	body = appendUint16(body, 0)
+	body = append(body, payload...)
+	if err := c.write(packetConnect<<4, body); err != nil {
+		return err
+	}
+
+	kind, resp, err := c.read()
+	if err != nil {
+		return err
+	}
+	if kind != packetConnAck || len(resp) != 2 {
+		return fmt.Errorf("unexpected MQTT packet type %d while connecting", kind)
+	}
+	if resp[1] != 0 {
+		return ConnectError(resp[1])
+	}
+	return nil
+}
+
+// Publish sends a message at QoS 1 and waits for the broker's
+// acknowledgement. Retained messages are kept by the broker and delivered to
+// later subscribers.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1 // packet identifiers must be non-zero
+	}
+	id := c.nextID
+
+	var header byte = packetPublish<<4 | 0x02 // QoS 1
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = appendUint16(body, id)
+	body = append(body, payload...)
+	if err := c.write(header, body); err != nil {
+		return err
+	}
+
+	kind, resp, err := c.read()
+	if err != nil {
+		return err
+	}
+	if kind != packetPubAck || len(resp) != 2 || binary.BigEndian.Uint16(resp) != id {
+		return fmt.Errorf("unexpected MQTT packet type %d while publishing %s", kind, topic)
+	}
+	return nil
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *Client) Close() error {
+	err := c.write(packetDisconnect<<4, nil)
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// write sends one control packet
+func (c *Client) write(header byte, body []byte) error {
+	packet := append([]byte{header}, encodeLength(len(body))...)
+	packet = append(packet, body...)
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(packet); err != nil {
+		return fmt.Errorf("MQTT write failed: %w", err)
+	}
+	return nil
+}
+
+// read receives one control packet, returning its type and body
+func (c *Client) read() (byte, []byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("MQTT read failed: %w", err)
+	}
+	length, err := decodeLength(c.r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("MQTT read failed: %w", err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, fmt.Errorf("MQTT read failed: %w", err)
+	}
+	return header >> 4, body, nil
+}
+
+// encodeLength encodes a remaining length as a variable byte integer
+func encodeLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+// decodeLength reads a variable byte integer of at most four bytes
+func decodeLength(r io.ByteReader) (int, error) {
+	n, shift := 0, 0
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n, nil
+		}
+		shift += 7
+	}
+	return 0, errors.New("malformed remaining length")
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	b = appendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
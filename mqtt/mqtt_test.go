@@ -0,0 +1,115 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// packet is a control packet as seen by the fake broker
+type packet struct {
+	header byte
+	body   []byte
+}
+
+// fakeBroker accepts one connection, answers CONNECT with returnCode and
+// acknowledges each PUBLISH, sending every packet it receives to the channel
+func fakeBroker(t *testing.T, returnCode byte) (string, <-chan packet) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	packets := make(chan packet, 16)
+	go func() {
+		defer close(packets)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			header, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			length, err := decodeLength(r)
+			if err != nil {
+				return
+			}
+			body := make([]byte, length)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			packets <- packet{header, body}
+
+			switch header >> 4 {
+			case packetConnect:
+				conn.Write([]byte{packetConnAck << 4, 2, 0, returnCode})
+			case packetPublish:
+				topicLen := int(body[0])<<8 | int(body[1])
+				id := body[2+topicLen : 4+topicLen]
+				conn.Write([]byte{packetPubAck << 4, 2, id[0], id[1]})
+			}
+		}
+	}()
+	return ln.Addr().String(), packets
+}
+
+func TestPublish(t *testing.T) {
+	addr, packets := fakeBroker(t, 0)
+	c, err := Dial(context.Background(), Options{Broker: "tcp://" + addr, ClientID: "test", Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if err := c.Publish("weather/home/temperature", []byte("17.5"), true); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	connect := <-packets
+	wantConnect := append(appendString(nil, "MQTT"), 4, 0xc2, 0, 0)
+	wantConnect = append(wantConnect, appendString(appendString(appendString(nil, "test"), "user"), "pass")...)
+	if connect.header != packetConnect<<4 || !bytes.Equal(connect.body, wantConnect) {
+		t.Errorf("CONNECT = %x %x; want %x", connect.header, connect.body, wantConnect)
+	}
+
+	publish := <-packets
+	wantPublish := append(appendString(nil, "weather/home/temperature"), 0, 1)
+	wantPublish = append(wantPublish, "17.5"...)
+	if publish.header != 0x33 || !bytes.Equal(publish.body, wantPublish) {
+		t.Errorf("PUBLISH = %x %q; want 33 %q", publish.header, publish.body, wantPublish)
+	}
+
+	if disconnect := <-packets; disconnect.header != packetDisconnect<<4 {
+		t.Errorf("last packet = %x; want DISCONNECT", disconnect.header)
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	addr, _ := fakeBroker(t, 5)
+	_, err := Dial(context.Background(), Options{Broker: addr})
+	if !errors.Is(err, ConnectError(5)) {
+		t.Errorf("Dial error = %v; want not authorized", err)
+	}
+}
+
+func TestEncodeLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, 2097152} {
+		got, err := decodeLength(bytes.NewReader(encodeLength(n)))
+		if err != nil || got != n {
+			t.Errorf("round trip of %d = %d, %v", n, got, err)
+		}
+	}
+	if got := encodeLength(321); !bytes.Equal(got, []byte{0xc1, 0x02}) {
+		t.Errorf("encodeLength(321) = %x; want c102", got)
+	}
+}
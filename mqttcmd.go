@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/mqtt"
+	"github.com/streek/go-weather/render"
+)
+
+// Defaults for MQTTConfig
+const (
+	defaultTopicPrefix     = "weather"
+	defaultDiscoveryPrefix = "homeassistant"
+	defaultMQTTClientID    = "go-weather"
+)
+
+// MQTTConfig configures publish-mqtt
+type MQTTConfig struct {
+	Broker          string `json:"broker"` // e.g. tcp://localhost:1883 or ssl://host:8883
+	Username        string `json:"username,omitempty"`
+	Password        string `json:"password,omitempty"` // or $MQTT_PASSWORD
+	ClientID        string `json:"client_id,omitempty"`
+	TopicPrefix     string `json:"topic_prefix,omitempty"`
+	Discovery       bool   `json:"discovery,omitempty"` // publish Home Assistant discovery
+	DiscoveryPrefix string `json:"discovery_prefix,omitempty"`
+}
+
+// mqttMessage is one message to publish, always retained
+type mqttMessage struct {
+	topic   string
+	payload []byte
+}
+
+// mqttField is a value published to its own topic and, with discovery,
+// announced to Home Assistant as a sensor
+type mqttField struct {
+	key         string
+	name        string
+	deviceClass string
+	stateClass  string
+	unit        func(units forecast.UnitSystem) string
+	value       func(r render.LocationReport) (string, bool)
+}
+
+// mqttFields lists the per-field topics
+var mqttFields = []mqttField{
+	{"temperature", "Temperature", "temperature", "measurement", forecast.TempUnit, func(r render.LocationReport) (string, bool) {
+		return formatValue(r.Current.Temperature), true
+	}},
+	{"wind_speed", "Wind speed", "wind_speed", "measurement", forecast.WindUnit, func(r render.LocationReport) (string, bool) {
+		return formatValue(r.Current.WindSpeed), true
+	}},
+	{"weather_code", "Weather code", "", "", nil, func(r render.LocationReport) (string, bool) {
+		return strconv.Itoa(r.Current.WeatherCode), true
+	}},
+	{"condition", "Condition", "", "", nil, func(r render.LocationReport) (string, bool) {
+		return r.Current.Condition, true
+	}},
+	{"temperature_max", "Today's high", "temperature", "", forecast.TempUnit, func(r render.LocationReport) (string, bool) {
+		if len(r.Daily) == 0 {
+			return "", false
+		}
+		return formatValue(r.Daily[0].TemperatureMax), true
+	}},
+	{"temperature_min", "Today's low", "temperature", "", forecast.TempUnit, func(r render.LocationReport) (string, bool) {
+		if len(r.Daily) == 0 {
+			return "", false
+		}
+		return formatValue(r.Daily[0].TemperatureMin), true
+	}},
+	{"precipitation_sum", "Today's precipitation", "precipitation", "", forecast.PrecipUnit, func(r render.LocationReport) (string, bool) {
+		if len(r.Daily) == 0 || math.IsNaN(r.Daily[0].PrecipitationSum) {
+			return "", false
+		}
+		return formatValue(r.Daily[0].PrecipitationSum), true
+	}},
+	{"observed", "Observed", "timestamp", "", nil, func(r render.LocationReport) (string, bool) {
+		return r.Current.Time, r.Current.Time != ""
+	}},
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// runPublishMQTT periodically publishes the forecast to an MQTT broker
+func runPublishMQTT(ctx context.Context, args []string) error {
+	config := loadConfig()
+	var settings MQTTConfig
+	if config.MQTT != nil {
+		settings = *config.MQTT
+	}
+
+	fs := flag.NewFlagSet("publish-mqtt", flag.ContinueOnError)
+	fs.StringVar(&settings.Broker, "broker", settings.Broker, "MQTT broker URL, e.g. tcp://localhost:1883")
+	fs.StringVar(&settings.Username, "username", settings.Username, "MQTT user name")
+	fs.StringVar(&settings.TopicPrefix, "prefix", settings.TopicPrefix, "Topic prefix (default weather)")
+	fs.BoolVar(&settings.Discovery, "discovery", settings.Discovery, "Publish Home Assistant MQTT discovery")
+	var zips locationList
+	fs.Var(&zips, "zip", "Locations to publish; repeat or separate with ;")
+	fs.Var(&zips, "z", "Short for -zip")
+	units := fs.String("units", "", "Units (metric or imperial)")
+	interval := fs.Duration("interval", 0, "Publish interval (default: whenever the cached forecast expires)")
+	once := fs.Bool("once", false, "Publish once and exit, e.g. from cron")
+	cmd := &Command{retries: -1}
+	fs.StringVar(&cmd.provider, "provider", "", "Weather data provider(s), comma-separated")
+	fs.BoolVar(&cmd.consensus, "consensus", false, "Blend forecasts from all configured providers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval != 0 && *interval < watchMinInterval {
+		return fmt.Errorf("publish interval must be at least %s", watchMinInterval)
+	}
+	if env := os.Getenv("MQTT_PASSWORD"); env != "" {
+		settings.Password = env
+	}
+	if settings.Broker == "" {
+		return fmt.Errorf("no MQTT broker; use -broker or set mqtt.broker in the config")
+	}
+
+	provider, err := cmd.newProvider(config)
+	if err != nil {
+		return err
+	}
+	if err := configureHTTP(config, cmd); err != nil {
+		return err
+	}
+	client := newClient(config)
+	client.Provider = provider
+	if *interval > 0 && *interval < client.Cache.TTL {
+		client.Cache.TTL = *interval
+	}
+
+	queries := config.expandLocations(zips)
+	if len(queries) == 0 {
+		queries = config.expandLocations(splitLocations(config.ZipCode))
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no location given; use -zip or save a default location")
+	}
+
+	unitSystem := forecast.UnitSystem(*units)
+	if unitSystem == "" {
+		unitSystem = config.Units
+	}
+	if unitSystem == "" {
+		unitSystem = forecast.UnitMetric
+	}
+	opts := forecast.Options{Daily: true, Hourly: true, Units: unitSystem}
+
+	publish := func(ctx context.Context) ([]render.Section, error) {
+		sections, err := fetchSections(ctx, client, &config, queries, opts, io.Discard, false, false)
+		if err != nil {
+			return nil, err
+		}
+		return sections, publishMQTT(ctx, settings, mqttMessages(settings, sections, unitSystem))
+	}
+	summary := func(w io.Writer, sections []render.Section) error {
+		labels := make([]string, len(sections))
+		for i, s := range sections {
+			labels[i] = s.Label
+		}
+		_, err := fmt.Fprintf(w, "Published weather for %s to %s\n", strings.Join(labels, ", "), settings.Broker)
+		return err
+	}
+
+	if *once {
+		sections, err := publish(ctx)
+		if err != nil {
+			return err
+		}
+		return summary(os.Stderr, sections)
+	}
+
+	w := &watcher{
+		out:      os.Stderr,
+		interval: *interval,
+		fetch:    publish,
+		draw:     summary,
+		expires: func(sections []render.Section) time.Time {
+			return firstExpiry(client, sections, opts)
+		},
+	}
+	return w.run(ctx)
+}
+
+// publishMQTT connects, publishes the messages as retained and disconnects
+func publishMQTT(ctx context.Context, settings MQTTConfig, messages []mqttMessage) error {
+	clientID := settings.ClientID
+	if clientID == "" {
+		clientID = defaultMQTTClientID
+	}
+	c, err := mqtt.Dial(ctx, mqtt.Options{
+		Broker:   settings.Broker,
+		ClientID: clientID,
+		Username: settings.Username,
+		Password: settings.Password,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range messages {
+		if err := c.Publish(m.topic, m.payload, true); err != nil {
+			c.Close()
+			return err
+		}
+	}
+	return c.Close()
+}
+
+// mqttMessages lays out the messages for each location: the JSON report at
+// <prefix>/<location>, each field at <prefix>/<location>/<field> and, with
+// discovery, a Home Assistant sensor config per field
+func mqttMessages(settings MQTTConfig, sections []render.Section, units forecast.UnitSystem) []mqttMessage {
+	prefix := settings.TopicPrefix
+	if prefix == "" {
+		prefix = defaultTopicPrefix
+	}
+	discoveryPrefix := settings.DiscoveryPrefix
+	if discoveryPrefix == "" {
+		discoveryPrefix = defaultDiscoveryPrefix
+	}
+
+	var messages []mqttMessage
+	report := render.NewReport(sections, render.Options{Daily: true, Hourly: true, Units: units})
+	for _, r := range report.Forecasts {
+		slug := topicSlug(r.Query)
+		base := prefix + "/" + slug
+		state, _ := json.Marshal(r)
+		messages = append(messages, mqttMessage{base, state})
+
+		for _, f := range mqttFields {
+			value, ok := f.value(r)
+			if !ok {
+				continue
+			}
+			messages = append(messages, mqttMessage{base + "/" + f.key, []byte(value)})
+			if settings.Discovery {
+				messages = append(messages, discoveryMessage(discoveryPrefix, base, slug, r, f, units))
+			}
+		}
+	}
+	return messages
+}
+
+// discoveryMessage announces one field as a Home Assistant sensor, grouped
+// into a device per location
+func discoveryMessage(discoveryPrefix, base, slug string, r render.LocationReport, f mqttField, units forecast.UnitSystem) mqttMessage {
+	nodeID := "go_weather_" + slug
+	config := map[string]interface{}{
+		"name":        f.name,
+		"unique_id":   nodeID + "_" + f.key,
+		"state_topic": base + "/" + f.key,
+		"device": map[string]interface{}{
+			"identifiers":  []string{nodeID},
+			"name":         "Weather " + r.Query,
+			"manufacturer": "go-weather",
+			"model":        r.Source,
+		},
+	}
+	if f.deviceClass != "" {
+		config["device_class"] = f.deviceClass
+	}
+	if f.stateClass != "" {
+		config["state_class"] = f.stateClass
+	}
+	if f.unit != nil {
+		config["unit_of_measurement"] = f.unit(units)
+	}
+	payload, _ := json.Marshal(config)
+	return mqttMessage{discoveryPrefix + "/sensor/" + nodeID + "/" + f.key + "/config", payload}
+}
+
+// topicSlug turns a location label into a topic level: lower case letters
+// and digits joined by underscores, so "New York, NY" becomes new_york_ny
+func topicSlug(label string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(label) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			pending = false
+			continue
+		}
+		pending = true
+	}
+	if b.Len() == 0 {
+		return "location"
+	}
+	return b.String()
+}
+
+// printPublishMQTTHelp lists the publish-mqtt command in the main help text
+func printPublishMQTTHelp() {
+	fmt.Printf("  publish-mqtt [-broker url] [-zip loc;loc...] [-discovery]\n")
+	fmt.Printf("        [-interval d] [-once]            Publish retained forecasts to MQTT topics\n\n")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
+)
+
+// locationList collects -z values; each may name several locations
+// separated by ";" since commas belong to the location itself
+type locationList []string
+
+func (l *locationList) String() string {
+	return strings.Join(*l, ";")
+}
+
+func (l *locationList) Set(s string) error {
+	*l = append(*l, splitLocations(s)...)
+	return nil
+}
+
+// splitLocations splits a ";"-separated location list, dropping blanks
+func splitLocations(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// expandLocations replaces location group names with their members
+func (c *Config) expandLocations(queries []string) []string {
+	var out []string
+	for _, q := range queries {
+		if members, ok := c.Groups[strings.ToLower(q)]; ok {
+			out = append(out, members...)
+			continue
+		}
+		out = append(out, q)
+	}
+	return out
+}
+
+// fetchSections resolves every query and fetches the forecasts concurrently.
+// Progress notes go to info, normally stderr so stdout only carries the report.
+// prompt lets an ambiguous query open the location picker.
+func fetchSections(ctx context.Context, client *weather.Client, config *Config, queries []string, opts forecast.Options, info io.Writer, showSource, prompt bool) ([]render.Section, error) {
+	sections := make([]render.Section, len(queries))
+
+	// Resolve one at a time so the ambiguity picker never interleaves
+	for i, q := range queries {
+		loc, err := locateQuery(ctx, client, config, q, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get coordinates for %s: %w", q, err)
+		}
+		sections[i].Label = q
+		sections[i].Location = loc
+		fmt.Fprintf(info, "Location detected: %s\n", loc)
+	}
+
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i := range sections {
+		wg.Add(1)
+		go func(s *render.Section, err *error) {
+			defer wg.Done()
+			s.Weather, s.Cached, *err = client.Forecast(ctx, s.Location, opts)
+		}(&sections[i], &errs[i])
+	}
+	wg.Wait()
+
+	for i, s := range sections {
+		suffix := ""
+		if len(sections) > 1 {
+			suffix = " for " + s.Label
+		}
+		if errs[i] != nil {
+			if suffix == "" {
+				return nil, errs[i]
+			}
+			return nil, fmt.Errorf("%s: %w", s.Label, errs[i])
+		}
+		if s.Cached {
+			fmt.Fprintf(info, "Using cached weather data%s\n", suffix)
+		}
+		if showSource {
+			fmt.Fprintf(info, "Data source%s: %s\n", suffix, s.Weather.Source)
+		}
+	}
+	return sections, nil
+}
+
+// locateQuery returns a saved location by name, or geocodes query, asking
+// which place is meant if prompt is set
+func locateQuery(ctx context.Context, client *weather.Client, config *Config, query string, prompt bool) (geocode.GeoLocation, error) {
+	if saved, ok := config.savedLocation(query); ok {
+		return saved.Location, nil
+	}
+	return resolveLocation(ctx, client, config, query, prompt)
+}
@@ -0,0 +1,151 @@
+// Package notify posts messages to chat services: Slack and Discord incoming
+// webhooks and Matrix rooms. Requests go through an api.Client. Matrix
+// requests are retried with backoff like any other; webhook posts are only
+// retried after a refused connection or a 429, since a timeout or server
+// error may come after the message was already posted.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/streek/go-weather/api"
+)
+
+// discordMaxContent is the length limit of a Discord message
+const discordMaxContent = 2000
+
+// Message is a titled list of lines, formatted for each service
+type Message struct {
+	Title string
+	Lines []string
+}
+
+// Text renders the message as plain text
+func (m Message) Text() string {
+	return strings.Join(append([]string{m.Title}, m.Lines...), "\n")
+}
+
+// Notifier delivers messages to one destination
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Slack posts to a Slack incoming webhook
+type Slack struct {
+	URL  string
+	HTTP *api.Client // nil uses api.DefaultClient
+}
+
+// slackEscaper escapes the characters Slack's mrkdwn treats as control
+// sequences
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Notify implements Notifier
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	lines := []string{"*" + slackEscaper.Replace(msg.Title) + "*"}
+	for _, l := range msg.Lines {
+		lines = append(lines, slackEscaper.Replace(l))
+	}
+	payload := map[string]interface{}{"text": strings.Join(lines, "\n")}
+	if err := send(ctx, webhookClient(s.HTTP), http.MethodPost, s.URL, nil, payload); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// Discord posts to a Discord webhook
+type Discord struct {
+	URL  string
+	HTTP *api.Client // nil uses api.DefaultClient
+}
+
+// Notify implements Notifier. Mentions in the text never ping anyone.
+func (d *Discord) Notify(ctx context.Context, msg Message) error {
+	content := "**" + msg.Title + "**\n" + strings.Join(msg.Lines, "\n")
+	if runes := []rune(content); len(runes) > discordMaxContent {
+		content = string(runes[:discordMaxContent-1]) + "…"
+	}
+	payload := map[string]interface{}{
+		"content":          content,
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}
+	if err := send(ctx, webhookClient(d.HTTP), http.MethodPost, d.URL, nil, payload); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	return nil
+}
+
+// Matrix sends m.room.message events to a room through the client-server API
+type Matrix struct {
+	Homeserver string // e.g. https://matrix.example.org
+	Room       string // room ID such as !abc:example.org
+	Token      string // access token of the sending user
+	HTTP       *api.Client
+}
+
+// txnCounter keeps Matrix transaction IDs unique within the process
+var txnCounter uint64
+
+// Notify implements Notifier. The transaction ID makes retries of the same
+// message idempotent.
+func (m *Matrix) Notify(ctx context.Context, msg Message) error {
+	txn := fmt.Sprintf("go-weather-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&txnCounter, 1))
+	endpoint := strings.TrimRight(m.Homeserver, "/") + "/_matrix/client/v3/rooms/" +
+		url.PathEscape(m.Room) + "/send/m.room.message/" + txn
+
+	formatted := []string{"<strong>" + html.EscapeString(msg.Title) + "</strong>"}
+	for _, l := range msg.Lines {
+		formatted = append(formatted, html.EscapeString(l))
+	}
+	payload := map[string]interface{}{
+		"msgtype":        "m.text",
+		"body":           msg.Text(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.Join(formatted, "<br>"),
+	}
+	header := http.Header{"Authorization": {"Bearer " + m.Token}}
+	if err := send(ctx, m.HTTP, http.MethodPut, endpoint, header, payload); err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	return nil
+}
+
+// webhookClient returns a copy of client that only retries posts the
+// webhook never received or turned away, so a retry cannot post twice
+func webhookClient(client *api.Client) *api.Client {
+	if client == nil {
+		client = api.DefaultClient
+	}
+	c := *client
+	c.Retry = api.RetryUnsent
+	return &c
+}
+
+// send encodes payload as JSON and delivers it, rejecting error responses
+func send(ctx context.Context, client *api.Client, method, endpoint string, header http.Header, payload interface{}) error {
+	if client == nil {
+		client = api.DefaultClient
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(ctx, method, endpoint, header, body)
+	if err != nil {
+		return err
+	}
+	return api.CheckResponse(resp.Response, resp.Body)
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/api"
+)
+
+// request is what the stand-in server received
+type request struct {
+	method, path, auth string
+	payload            map[string]interface{}
+}
+
+// standIn answers with the given statuses in turn, then 200, recording each
+// request
+func standIn(t *testing.T, statuses ...int) (*httptest.Server, *[]request) {
+	var got []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := request{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization")}
+		json.Unmarshal(body, &req.payload)
+		got = append(got, req)
+		if len(got) <= len(statuses) {
+			w.WriteHeader(statuses[len(got)-1])
+			w.Write([]byte("invalid_token"))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &got
+}
+
+// fastClient retries without noticeable delays
+func fastClient() *api.Client {
+	c := api.NewClient()
+	c.BaseDelay, c.MaxDelay = time.Millisecond, time.Millisecond
+	return c
+}
+
+var message = Message{Title: "Weather for Paris", Lines: []string{"Now: 18°C <sunny>", "@everyone Tomorrow: rain"}}
+
+func TestSlack(t *testing.T) {
+	server, got := standIn(t)
+	s := &Slack{URL: server.URL + "/services/T/B/X", HTTP: fastClient()}
+	if err := s.Notify(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	want := "*Weather for Paris*\nNow: 18°C &lt;sunny&gt;\n@everyone Tomorrow: rain"
+	if text := (*got)[0].payload["text"]; text != want {
+		t.Errorf("text = %q; want %q", text, want)
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	server, got := standIn(t)
+	d := &Discord{URL: server.URL, HTTP: fastClient()}
+	if err := d.Notify(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	p := (*got)[0].payload
+	if !strings.HasPrefix(p["content"].(string), "**Weather for Paris**\n") {
+		t.Errorf("content = %q", p["content"])
+	}
+	if mentions, _ := p["allowed_mentions"].(map[string]interface{}); mentions == nil || len(mentions["parse"].([]interface{})) != 0 {
+		t.Errorf("allowed_mentions = %v; want no pings", p["allowed_mentions"])
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	server, got := standIn(t)
+	m := &Matrix{Homeserver: server.URL + "/", Room: "!room:example.org", Token: "secret", HTTP: fastClient()}
+	if err := m.Notify(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if req.method != http.MethodPut || !strings.HasPrefix(req.path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/") {
+		t.Errorf("request = %s %s", req.method, req.path)
+	}
+	if req.auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", req.auth)
+	}
+	if req.payload["msgtype"] != "m.text" || req.payload["formatted_body"] != "<strong>Weather for Paris</strong><br>Now: 18°C &lt;sunny&gt;<br>@everyone Tomorrow: rain" {
+		t.Errorf("payload = %v", req.payload)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	// The webhook may have posted the message before failing, so it is
+	// not sent again
+	server, got := standIn(t, http.StatusInternalServerError)
+	if err := (&Discord{URL: server.URL, HTTP: fastClient()}).Notify(context.Background(), message); !errors.Is(err, api.ErrServer) || len(*got) != 1 {
+		t.Errorf("discord: error = %v after %d requests; want one failed request", err, len(*got))
+	}
+
+	// A 429 means nothing was posted
+	server, got = standIn(t, http.StatusTooManyRequests)
+	if err := (&Slack{URL: server.URL, HTTP: fastClient()}).Notify(context.Background(), message); err != nil || len(*got) != 2 {
+		t.Errorf("slack: error = %v after %d requests; want a retry after the 429", err, len(*got))
+	}
+
+	// Matrix transaction IDs make retries safe
+	server, got = standIn(t, http.StatusInternalServerError)
+	m := &Matrix{Homeserver: server.URL, Room: "!room:example.org", Token: "secret", HTTP: fastClient()}
+	if err := m.Notify(context.Background(), message); err != nil || len(*got) != 2 || (*got)[0].path != (*got)[1].path {
+		t.Errorf("matrix: error = %v after %d requests; want a retry with the same transaction", err, len(*got))
+	}
+}
+
+func TestRejected(t *testing.T) {
+	server, _ := standIn(t, http.StatusForbidden)
+	err := (&Slack{URL: server.URL, HTTP: fastClient()}).Notify(context.Background(), message)
+	if !errors.Is(err, api.ErrBadRequest) || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("error = %v; want the rejection", err)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/alert"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/notify"
+	"github.com/streek/go-weather/render"
+)
+
+// notifySummaryDays is how many days of the daily forecast a summary lists
+const notifySummaryDays = 3
+
+// NotifyConfig lists where to post one location's weather
+type NotifyConfig struct {
+	Targets []NotifyTarget `json:"targets"`
+	// Rules, when set, limit posts to the alert rules that fire
+	Rules []string `json:"rules,omitempty"`
+}
+
+// NotifyTarget is a Slack or Discord webhook or a Matrix room
+type NotifyTarget struct {
+	Type       string `json:"type"`                 // slack, discord or matrix
+	URL        string `json:"url,omitempty"`        // Slack and Discord webhook URL
+	Homeserver string `json:"homeserver,omitempty"` // Matrix, e.g. https://matrix.org
+	Room       string `json:"room,omitempty"`       // Matrix room ID, e.g. !abc:matrix.org
+	Token      string `json:"token,omitempty"`      // Matrix access token, or $MATRIX_TOKEN
+}
+
+// newNotifier checks a target and returns its notifier
+func newNotifier(t NotifyTarget) (notify.Notifier, error) {
+	switch strings.ToLower(t.Type) {
+	case "slack":
+		if t.URL == "" {
+			return nil, fmt.Errorf("slack target needs a webhook url")
+		}
+		return &notify.Slack{URL: t.URL}, nil
+	case "discord":
+		if t.URL == "" {
+			return nil, fmt.Errorf("discord target needs a webhook url")
+		}
+		return &notify.Discord{URL: t.URL}, nil
+	case "matrix":
+		token := t.Token
+		if token == "" {
+			token = os.Getenv("MATRIX_TOKEN")
+		}
+		if t.Homeserver == "" || t.Room == "" || token == "" {
+			return nil, fmt.Errorf("matrix target needs a homeserver, room and token")
+		}
+		return &notify.Matrix{Homeserver: t.Homeserver, Room: t.Room, Token: token}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier type %q (use slack, discord or matrix)", t.Type)
+}
+
+// notifyConfig finds a location's notify settings, ignoring case
+func (c *Config) notifyConfig(query string) (NotifyConfig, bool) {
+	for name, n := range c.Notify {
+		if strings.EqualFold(name, query) {
+			return n, true
+		}
+	}
+	return NotifyConfig{}, false
+}
+
+// notifyJob is what to post for one location, and where
+type notifyJob struct {
+	targets []*notifyState
+	rules   []alert.Rule
+}
+
+// notifyState is one target of a job
+type notifyState struct {
+	notifier  notify.Notifier
+	kind      string
+	lastAlert string // fired rules last posted here, so watch mode posts changes only
+}
+
+// runNotify posts a forecast summary, or the alert rules that fire, to each
+// location's Slack, Discord and Matrix targets
+func runNotify(ctx context.Context, args []string) error {
+	config := loadConfig()
+
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	var zips locationList
+	fs.Var(&zips, "zip", "Locations to notify for; repeat or separate with ; (default: all in the config)")
+	fs.Var(&zips, "z", "Short for -zip")
+	var ruleTexts locationList
+	fs.Var(&ruleTexts, "rule", "Only post when an alert rule fires; repeat or separate with ;")
+	units := fs.String("units", "", "Units (metric or imperial)")
+	dryRun := fs.Bool("dry-run", false, "Print the messages instead of posting them")
+	var watch watchFlag
+	fs.Var(&watch, "watch", "Keep running and post when the data expires, or every interval with -watch=6h")
+	cmd := &Command{retries: -1}
+	fs.StringVar(&cmd.provider, "provider", "", "Weather data provider(s), comma-separated")
+	fs.BoolVar(&cmd.consensus, "consensus", false, "Blend forecasts from all configured providers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := noArgs(fs.Args(), watch); err != nil {
+		return err
+	}
+
+	unitSystem := forecast.UnitSystem(*units)
+	if unitSystem == "" {
+		unitSystem = config.Units
+	}
+	if unitSystem == "" {
+		unitSystem = forecast.UnitMetric
+	}
+
+	queries := config.expandLocations(zips)
+	if len(queries) == 0 {
+		for name := range config.Notify {
+			queries = append(queries, name)
+		}
+		sort.Strings(queries)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no notifiers configured; add targets under \"notify\" in %s", getConfigPath())
+	}
+
+	jobs := make(map[string]*notifyJob, len(queries))
+	for _, q := range queries {
+		settings, ok := config.notifyConfig(q)
+		if !ok && !*dryRun {
+			return fmt.Errorf("no notify targets configured for %s", q)
+		}
+		job := &notifyJob{}
+		for _, t := range settings.Targets {
+			n, err := newNotifier(t)
+			if err != nil {
+				return fmt.Errorf("notify targets for %s: %w", q, err)
+			}
+			job.targets = append(job.targets, &notifyState{notifier: n, kind: strings.ToLower(t.Type)})
+		}
+		texts := settings.Rules
+		if len(ruleTexts) > 0 {
+			texts = ruleTexts
+		}
+		for _, text := range texts {
+			rule, err := alert.Parse(text, unitSystem)
+			if err != nil {
+				return err
+			}
+			job.rules = append(job.rules, rule)
+		}
+		jobs[q] = job
+	}
+
+	provider, err := cmd.newProvider(config)
+	if err != nil {
+		return err
+	}
+	if err := configureHTTP(config, cmd); err != nil {
+		return err
+	}
+	client := newClient(config)
+	client.Provider = provider
+	if watch.interval > 0 && watch.interval < client.Cache.TTL {
+		client.Cache.TTL = watch.interval
+	}
+	opts := forecast.Options{Daily: true, Hourly: true, Units: unitSystem}
+
+	var posted []string
+	post := func(ctx context.Context) ([]render.Section, error) {
+		sections, err := fetchSections(ctx, client, &config, queries, opts, io.Discard, false, false)
+		if err != nil {
+			return nil, err
+		}
+		posted = posted[:0]
+		var failures []string
+		now := time.Now()
+		for _, s := range sections {
+			job := jobs[s.Label]
+			msg := summaryMessage(s, unitSystem, now)
+			var fired string
+			if len(job.rules) > 0 {
+				var empty []string
+				msg, fired, empty = alertMessage(s, job.rules, unitSystem, now)
+				for _, text := range empty {
+					posted = append(posted, fmt.Sprintf("%s: no forecast data for %q", s.Label, text))
+				}
+				if fired == "" {
+					for _, t := range job.targets {
+						t.lastAlert = ""
+					}
+					posted = append(posted, fmt.Sprintf("%s: no new alerts", s.Label))
+					continue
+				}
+			}
+
+			if *dryRun {
+				fmt.Println(msg.Text())
+				fmt.Println()
+				continue
+			}
+			kinds, errs := job.deliver(ctx, msg, fired, len(job.rules) > 0 && watch.enabled)
+			for _, err := range errs {
+				failures = append(failures, fmt.Sprintf("%s: %v", s.Label, err))
+			}
+			switch {
+			case len(kinds) > 0:
+				posted = append(posted, fmt.Sprintf("%s: posted %q to %s", s.Label, msg.Title, strings.Join(kinds, ", ")))
+			case len(errs) == 0 && len(job.rules) > 0:
+				posted = append(posted, fmt.Sprintf("%s: no new alerts", s.Label))
+			}
+		}
+		if len(failures) > 0 {
+			return sections, fmt.Errorf("notification failed: %s", strings.Join(failures, "; "))
+		}
+		return sections, nil
+	}
+	summary := func(w io.Writer, sections []render.Section) error {
+		if len(posted) == 0 {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, strings.Join(posted, "\n"))
+		return err
+	}
+
+	if !watch.enabled {
+		sections, err := post(ctx)
+		if err != nil {
+			return err
+		}
+		return summary(os.Stderr, sections)
+	}
+
+	w := &watcher{
+		out:      os.Stderr,
+		interval: watch.interval,
+		fetch:    post,
+		draw:     summary,
+		expires: func(sections []render.Section) time.Time {
+			return firstExpiry(client, sections, opts)
+		},
+	}
+	return w.run(ctx)
+}
+
+// deliver posts msg to the job's targets and returns the kinds it reached.
+// With changesOnly, targets that were last sent the same fired rules are
+// skipped, so one that failed is retried later without re-posting to the
+// others.
+func (job *notifyJob) deliver(ctx context.Context, msg notify.Message, fired string, changesOnly bool) (kinds []string, errs []error) {
+	for _, t := range job.targets {
+		if changesOnly && fired == t.lastAlert {
+			continue
+		}
+		if err := t.notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t.lastAlert = fired
+		kinds = append(kinds, t.kind)
+	}
+	return kinds, errs
+}
+
+// summaryMessage describes the current conditions and the next few days
+func summaryMessage(s render.Section, units forecast.UnitSystem, now time.Time) notify.Message {
+	report := render.NewReport([]render.Section{s}, render.Options{Daily: true, Units: units})
+	r := report.Forecasts[0]
+	tempUnit := forecast.TempUnit(units)
+
+	msg := notify.Message{Title: "Weather for " + s.Location.String()}
+	msg.Lines = append(msg.Lines, fmt.Sprintf("Now: %.1f%s, %s, wind %.0f %s",
+		r.Current.Temperature, tempUnit, r.Current.Condition, r.Current.WindSpeed, forecast.WindUnit(units)))
+
+	local := now.In(s.Location.Zone())
+	today := local.Format("2006-01-02")
+	tomorrow := local.AddDate(0, 0, 1).Format("2006-01-02")
+	for _, d := range r.Daily {
+		if d.Date < today {
+			continue
+		}
+		if len(msg.Lines) > notifySummaryDays {
+			break
+		}
+		label := d.Date
+		switch d.Date {
+		case today:
+			label = "Today"
+		case tomorrow:
+			label = "Tomorrow"
+		default:
+			if t, err := time.Parse("2006-01-02", d.Date); err == nil {
+				label = t.Format("Mon Jan 2")
+			}
+		}
+		line := fmt.Sprintf("%s: %s, %.0f–%.0f%s", label, d.Condition, d.TemperatureMin, d.TemperatureMax, tempUnit)
+		if !math.IsNaN(d.PrecipitationSum) {
+			line += fmt.Sprintf(", %.1f%s precipitation", d.PrecipitationSum, forecast.PrecipUnit(units))
+		}
+		msg.Lines = append(msg.Lines, line)
+	}
+	return msg
+}
+
+// alertMessage lists the rules that fire for a section. fired joins their
+// texts, so it is empty when none did and changes when a different set does.
+// empty lists the rules the forecast has no data for, such as precipitation
+// from a provider that does not report it.
+func alertMessage(s render.Section, rules []alert.Rule, units forecast.UnitSystem, now time.Time) (msg notify.Message, fired string, empty []string) {
+	msg.Title = "Weather alert for " + s.Location.String()
+	var texts []string
+	for _, r := range evalRules(rules, []render.Section{s}, now) {
+		if r.Fired {
+			msg.Lines = append(msg.Lines, r.Rule.Text+": "+resultOutcome(r, units))
+			texts = append(texts, r.Rule.Text)
+		}
+		if r.Empty {
+			empty = append(empty, r.Rule.Text)
+		}
+	}
+	return msg, strings.Join(texts, "\n"), empty
+}
+
+// printNotifyHelp lists the notify command in the main help text
+func printNotifyHelp() {
+	fmt.Printf("  notify [-zip loc] [-rule rule]... [-watch[=interval]] [-dry-run]\n")
+	fmt.Printf("                                         Post a forecast summary, or fired alert\n")
+	fmt.Printf("                                         rules, to Slack, Discord or Matrix\n\n")
+}
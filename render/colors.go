@@ -0,0 +1,152 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// ANSI color codes
+const (
+	colorReset   = "\033[0m"
+	colorRed     = "\033[31m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorBlue    = "\033[34m"
+	colorMagenta = "\033[35m"
+	colorCyan    = "\033[36m"
+	colorWhite   = "\033[37m"
+)
+
+// Temperature bands from very cold to very hot, with their colors
+var (
+	tempANSIColors = []string{colorBlue, colorCyan, colorWhite, colorGreen, colorYellow, colorMagenta, colorRed}
+	tempHexColors  = []string{"#5f87ff", "#5fd7ff", "#ffffff", "#87d75f", "#ffd75f", "#ff87d7", "#ff5f5f"}
+)
+
+// tempBand returns the index of temp's band in the color tables
+func tempBand(temp float64, unitSystem forecast.UnitSystem) int {
+	// Convert to Celsius for standard comparison if needed
+	tempC := temp
+	if unitSystem == forecast.UnitImperial {
+		tempC = (temp - 32) * 5 / 9
+	}
+
+	// Color based on temperature ranges (in Celsius)
+	switch {
+	case tempC < -10:
+		return 0 // Very cold
+	case tempC < 0:
+		return 1 // Cold
+	case tempC < 15:
+		return 2 // Cool
+	case tempC < 25:
+		return 3 // Pleasant
+	case tempC < 30:
+		return 4 // Warm
+	case tempC < 35:
+		return 5 // Hot
+	default:
+		return 6 // Very hot
+	}
+}
+
+// ColorizeTemp applies color to temperature based on its value
+func ColorizeTemp(temp float64, unitSystem forecast.UnitSystem) string {
+	colorCode := tempANSIColors[tempBand(temp, unitSystem)]
+
+	// Format with units
+	unit := forecast.TempUnit(unitSystem)
+	return fmt.Sprintf("%s%.1f%s%s", colorCode, temp, unit, colorReset)
+}
+
+// TempColor returns the "#rrggbb" color for a temperature, matching ColorizeTemp
+func TempColor(temp float64, unitSystem forecast.UnitSystem) string {
+	return tempHexColors[tempBand(temp, unitSystem)]
+}
+
+// WeatherDescription converts weather code to human-readable description
+func WeatherDescription(code int) string {
+	descriptions := map[int]string{
+		0:  "Clear sky",
+		1:  "Mainly clear",
+		2:  "Partly cloudy",
+		3:  "Overcast",
+		45: "Fog",
+		48: "Depositing rime fog",
+		51: "Light drizzle",
+		53: "Moderate drizzle",
+		55: "Dense drizzle",
+		56: "Light freezing drizzle",
+		57: "Dense freezing drizzle",
+		61: "Slight rain",
+		63: "Moderate rain",
+		65: "Heavy rain",
+		66: "Light freezing rain",
+		67: "Heavy freezing rain",
+		71: "Slight snow fall",
+		73: "Moderate snow fall",
+		75: "Heavy snow fall",
+		77: "Snow grains",
+		80: "Slight rain showers",
+		81: "Moderate rain showers",
+		82: "Violent rain showers",
+		85: "Slight snow showers",
+		86: "Heavy snow showers",
+		95: "Thunderstorm",
+		96: "Thunderstorm with slight hail",
+		99: "Thunderstorm with heavy hail",
+	}
+
+	if desc, ok := descriptions[code]; ok {
+		return desc
+	}
+	return "Unknown"
+}
+
+// WeatherIcon returns an emoji for a weather code
+func WeatherIcon(code int) string {
+	switch {
+	case code == 0:
+		return "☀"
+	case code == 1:
+		return "🌤"
+	case code == 2:
+		return "⛅"
+	case code == 3:
+		return "☁"
+	case code == 45 || code == 48:
+		return "🌫"
+	case code >= 51 && code <= 57:
+		return "🌦"
+	case code >= 61 && code <= 67, code >= 80 && code <= 82:
+		return "🌧"
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return "🌨"
+	case code >= 95:
+		return "⛈"
+	}
+	return "?"
+}
+
+// WeatherCategory groups weather codes into broad conditions such as "rain"
+// or "thunderstorm", suitable as CSS classes
+func WeatherCategory(code int) string {
+	switch {
+	case code == 0 || code == 1:
+		return "clear"
+	case code == 2 || code == 3:
+		return "cloudy"
+	case code == 45 || code == 48:
+		return "fog"
+	case code >= 51 && code <= 57:
+		return "drizzle"
+	case code >= 61 && code <= 67, code >= 80 && code <= 82:
+		return "rain"
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return "snow"
+	case code >= 95:
+		return "thunderstorm"
+	}
+	return "unknown"
+}
@@ -0,0 +1,233 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+// Section is one location's forecast in a report
+type Section struct {
+	Label    string // the query as the user typed it
+	Location geocode.GeoLocation
+	Weather  forecast.WeatherData
+	Cached   bool // Weather came from the cache
+}
+
+// Column widths for the comparison table
+const (
+	compareLabelWidth = 12
+	compareCellWidth  = 18
+)
+
+// CompareText writes one text section per location
+func CompareText(w io.Writer, sections []Section, opts Options) {
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "== %s ==\n", s.Label)
+		opts.Zone = s.Location.Zone()
+		Text(w, s.Weather, opts)
+	}
+}
+
+// CompareTable writes current conditions and each forecast day side by side,
+// with one column per location
+func CompareTable(w io.Writer, sections []Section, opts Options) {
+	width := compareLabelWidth + 4 + len(sections)*(compareCellWidth+3)
+	tempUnit := forecast.TempUnit(opts.Units)
+	windUnit := forecast.WindUnit(opts.Units)
+	precipUnit := forecast.PrecipUnit(opts.Units)
+
+	temp := func(v float64) string {
+		if opts.Colors {
+			return ColorizeTemp(v, opts.Units)
+		}
+		return fmt.Sprintf("%.1f%s", v, tempUnit)
+	}
+	header := func(first string) {
+		printLine(w, width)
+		compareRow(w, first, sections, func(s Section) string { return s.Label })
+		printLine(w, width)
+	}
+
+	fmt.Fprintln(w, "Current Weather:")
+	header("")
+	compareRow(w, "Temperature", sections, func(s Section) string {
+		return temp(s.Weather.CurrentWeather.Temperature)
+	})
+	compareRow(w, "High/Low", sections, func(s Section) string {
+		i := dayIndex(s.Weather, time.Now().In(s.Location.Zone()).Format("2006-01-02"))
+		if i < 0 {
+			return "-"
+		}
+		return temp(s.Weather.Daily.TemperatureMax[i]) + "/" + temp(s.Weather.Daily.TemperatureMin[i])
+	})
+	compareRow(w, "Wind", sections, func(s Section) string {
+		return fmt.Sprintf("%.1f %s", s.Weather.CurrentWeather.WindSpeed, windUnit)
+	})
+	compareRow(w, "Time", sections, func(s Section) string {
+		return formatTime(s.Weather.CurrentWeather.Time)
+	})
+	compareRow(w, "Condition", sections, func(s Section) string {
+		return WeatherDescription(s.Weather.CurrentWeather.WeatherCode)
+	})
+	printLine(w, width)
+
+	if opts.Daily {
+		days := unionTimes(sections, func(d forecast.WeatherData) []string { return d.Daily.Time })
+		if len(days) > 0 {
+			fmt.Fprintln(w, "\n7-Day Forecast:")
+			header("Date")
+			for n, day := range days {
+				if n > 0 {
+					printLine(w, width)
+				}
+				t, _ := time.Parse("2006-01-02", day)
+				daily := func(f func(d forecast.WeatherData, i int) string) func(Section) string {
+					return func(s Section) string {
+						i := dayIndex(s.Weather, day)
+						if i < 0 {
+							return "-"
+						}
+						return f(s.Weather, i)
+					}
+				}
+				compareRow(w, t.Format("Mon Jan 2"), sections, daily(func(d forecast.WeatherData, i int) string {
+					return WeatherDescription(d.Daily.WeatherCode[i])
+				}))
+				compareRow(w, "  Low/High", sections, daily(func(d forecast.WeatherData, i int) string {
+					return temp(d.Daily.TemperatureMin[i]) + "/" + temp(d.Daily.TemperatureMax[i])
+				}))
+				compareRow(w, "  Precip", sections, daily(func(d forecast.WeatherData, i int) string {
+					return FormatPrecip(d.Daily.PrecipitationSum[i], precipUnit)
+				}))
+			}
+			printLine(w, width)
+		}
+	}
+
+	if opts.Hourly {
+		hours := unionTimes(sections, func(d forecast.WeatherData) []string { return d.Hourly.Time })
+		if len(hours) > 24 {
+			hours = hours[:24]
+		}
+		if len(hours) > 0 {
+			fmt.Fprintln(w, "\nHourly Forecast (next 24h):")
+			header("Time")
+			for _, hour := range hours {
+				compareRow(w, formatTime(hour), sections, func(s Section) string {
+					for i, t := range s.Weather.Hourly.Time {
+						if t == hour {
+							return temp(s.Weather.Hourly.Temperature[i]) + " " + WeatherDescription(s.Weather.Hourly.WeatherCode[i])
+						}
+					}
+					return "-"
+				})
+			}
+			printLine(w, width)
+		}
+	}
+}
+
+// compareRow writes a labelled table row with one cell per section
+func compareRow(w io.Writer, label string, sections []Section, cell func(Section) string) {
+	var b strings.Builder
+	b.WriteString("| " + Fit(label, compareLabelWidth) + " |")
+	for _, s := range sections {
+		b.WriteString(" " + Fit(cell(s), compareCellWidth) + " |")
+	}
+	fmt.Fprintln(w, b.String())
+}
+
+// dayIndex returns the index of date in the daily forecast, or -1
+func dayIndex(weather forecast.WeatherData, date string) int {
+	for i, day := range weather.Daily.Time {
+		if day == date {
+			return i
+		}
+	}
+	return -1
+}
+
+// unionTimes merges and sorts the time keys of every section
+func unionTimes(sections []Section, times func(forecast.WeatherData) []string) []string {
+	seen := map[string]bool{}
+	var all []string
+	for _, s := range sections {
+		for _, t := range times(s.Weather) {
+			if !seen[t] {
+				seen[t] = true
+				all = append(all, t)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// Fit truncates or pads s to width visible characters, ignoring ANSI
+// color codes so colored text stays aligned
+func Fit(s string, width int) string {
+	n := visibleWidth(s)
+	if n > width && width < 3 {
+		return truncateVisible(s, width)
+	}
+	if n > width {
+		s = truncateVisible(s, width-3) + "..."
+		n = width
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// truncateVisible keeps the first n visible runes of s, closing any color
+func truncateVisible(s string, n int) string {
+	var b strings.Builder
+	colored := false
+	for len(s) > 0 && n > 0 {
+		if s[0] == '\033' {
+			end := strings.IndexByte(s, 'm')
+			if end < 0 {
+				break
+			}
+			b.WriteString(s[:end+1])
+			colored = s[:end+1] != colorReset
+			s = s[end+1:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		b.WriteString(s[:size])
+		s = s[size:]
+		n--
+	}
+	if colored {
+		b.WriteString(colorReset)
+	}
+	return b.String()
+}
+
+// visibleWidth counts the runes of s that are not part of an ANSI escape
+func visibleWidth(s string) int {
+	n := 0
+	for len(s) > 0 {
+		if s[0] == '\033' {
+			end := strings.IndexByte(s, 'm')
+			if end < 0 {
+				break
+			}
+			s = s[end+1:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		n++
+	}
+	return n
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestFit(t *testing.T) {
+	colored := ColorizeTemp(20, forecast.UnitMetric)
+	tests := []struct {
+		in      string
+		visible string
+	}{
+		{"Clear", "Clear     "},
+		{"Thunderstorm with hail", "Thunder..."},
+		{colored, "20.0°C    "},
+		{colored + " Partly cloudy", "20.0°C ..."},
+	}
+	for _, tt := range tests {
+		got := Fit(tt.in, 10)
+		if visibleWidth(got) != 10 {
+			t.Errorf("Fit(%q) is %d wide; want 10", tt.in, visibleWidth(got))
+		}
+		if plain := stripANSI(got); plain != tt.visible {
+			t.Errorf("Fit(%q) = %q; want %q", tt.in, plain, tt.visible)
+		}
+	}
+}
+
+func TestCompareTable(t *testing.T) {
+	day := func(date string, max float64) forecast.WeatherData {
+		var d forecast.WeatherData
+		d.Daily.Time = []string{date}
+		d.Daily.WeatherCode = []int{0}
+		d.Daily.TemperatureMax = []float64{max}
+		d.Daily.TemperatureMin = []float64{max - 10}
+		d.Daily.PrecipitationSum = []float64{0}
+		return d
+	}
+	sections := []Section{
+		{Label: "home", Weather: day("2024-05-01", 20)},
+		{Label: "Paris, France", Weather: day("2024-05-02", 15)},
+	}
+
+	var out strings.Builder
+	CompareTable(&out, sections, Options{Daily: true, Units: forecast.UnitMetric})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+	width := visibleWidth(lines[1])
+	for _, line := range lines {
+		if strings.HasPrefix(line, "|") && visibleWidth(line) != width {
+			t.Errorf("misaligned row %q", line)
+		}
+	}
+	for _, want := range []string{"| Wed May 1    | Clear sky          | -                  |", "| Thu May 2    | -                  | Clear sky          |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing row %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+// stripANSI removes color codes from s
+func stripANSI(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		if s[0] == '\033' {
+			s = s[strings.IndexByte(s, 'm')+1:]
+			continue
+		}
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+	return b.String()
+}
+
+func TestTodayInLocationZone(t *testing.T) {
+	// Kiritimati and Baker Island are 26 hours apart, so their dates differ
+	// from each other and usually from the machine's
+	var sections []Section
+	for _, name := range []string{"Pacific/Kiritimati", "Etc/GMT+12"} {
+		zone, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skipf("time zone data unavailable: %v", err)
+		}
+		today := time.Now().In(zone)
+		var d forecast.WeatherData
+		for i, max := range []float64{11, 22, 33} {
+			d.Daily.Time = append(d.Daily.Time, today.AddDate(0, 0, i-1).Format("2006-01-02"))
+			d.Daily.WeatherCode = append(d.Daily.WeatherCode, 0)
+			d.Daily.TemperatureMax = append(d.Daily.TemperatureMax, max)
+			d.Daily.TemperatureMin = append(d.Daily.TemperatureMin, max-10)
+			d.Daily.PrecipitationSum = append(d.Daily.PrecipitationSum, 0)
+		}
+		sections = append(sections, Section{Label: name, Weather: d, Location: geocode.GeoLocation{Timezone: name}})
+	}
+
+	var out strings.Builder
+	CompareTable(&out, sections, Options{Units: forecast.UnitMetric})
+	if want := "| High/Low     | 22.0°C/12.0°C      | 22.0°C/12.0°C      |"; !strings.Contains(out.String(), want) {
+		t.Errorf("missing row %q in:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	CompareText(&out, sections, Options{Units: forecast.UnitMetric})
+	if got := strings.Count(out.String(), "High/Low: 22.0°C/12.0°C"); got != 2 {
+		t.Errorf("text shows today's high/low %d times; want 2:\n%s", got, out.String())
+	}
+}
@@ -0,0 +1,247 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// csvSeries is one table of the CSV/TSV export
+type csvSeries struct {
+	columns []csvColumn
+	rows    func(weather forecast.WeatherData) int
+}
+
+// csvColumn is one exported field; base is its name without the unit suffix
+type csvColumn struct {
+	name  string
+	base  string
+	value func(s Section, zone *time.Location, i int) string
+}
+
+// CSV writes the requested series as delimited tables with a header row.
+// Use ',' for CSV and '\t' for TSV. The daily and hourly series are written
+// as separate tables divided by a blank line; without either, the current
+// conditions are written instead. Several sections add a location column.
+func CSV(w io.Writer, sections []Section, opts Options, comma rune) error {
+	var series []csvSeries
+	if opts.Daily {
+		series = append(series, dailySeries(opts.Units))
+	}
+	if opts.Hourly {
+		series = append(series, hourlySeries(opts.Units))
+	}
+	if len(series) == 0 {
+		series = append(series, currentSeries(opts.Units))
+	}
+
+	if err := checkColumns(series, opts.Columns); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	written := 0
+	for _, s := range series {
+		columns := selectColumns(s.columns, opts.Columns)
+		if len(columns) == 0 {
+			continue
+		}
+		if written > 0 {
+			cw.Write(nil) // blank line between tables
+		}
+		written++
+
+		var header []string
+		if len(sections) > 1 {
+			header = append(header, "location")
+		}
+		for _, c := range columns {
+			header = append(header, c.name)
+		}
+		cw.Write(header)
+
+		for _, section := range sections {
+			zone := sectionZone(section)
+			for i := 0; i < s.rows(section.Weather); i++ {
+				var row []string
+				if len(sections) > 1 {
+					row = append(row, section.Label)
+				}
+				for _, c := range columns {
+					row = append(row, c.value(section, zone, i))
+				}
+				cw.Write(row)
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// dailySeries lists the columns of the daily forecast
+func dailySeries(units forecast.UnitSystem) csvSeries {
+	temp, precip := tempSuffix(units), precipSuffix(units)
+	return csvSeries{
+		rows: func(d forecast.WeatherData) int { return len(d.Daily.Time) },
+		columns: []csvColumn{
+			{"date", "date", func(s Section, _ *time.Location, i int) string { return s.Weather.Daily.Time[i] }},
+			{"weather_code", "weather_code", func(s Section, _ *time.Location, i int) string {
+				return strconv.Itoa(s.Weather.Daily.WeatherCode[i])
+			}},
+			{"condition", "condition", func(s Section, _ *time.Location, i int) string {
+				return WeatherDescription(s.Weather.Daily.WeatherCode[i])
+			}},
+			{"temperature_min" + temp, "temperature_min", func(s Section, _ *time.Location, i int) string {
+				return formatFloat(s.Weather.Daily.TemperatureMin[i])
+			}},
+			{"temperature_max" + temp, "temperature_max", func(s Section, _ *time.Location, i int) string {
+				return formatFloat(s.Weather.Daily.TemperatureMax[i])
+			}},
+			{"precipitation_sum" + precip, "precipitation_sum", func(s Section, _ *time.Location, i int) string {
+				return formatFloat(s.Weather.Daily.PrecipitationSum[i])
+			}},
+		},
+	}
+}
+
+// hourlySeries lists the columns of the hourly forecast
+func hourlySeries(units forecast.UnitSystem) csvSeries {
+	temp, precip := tempSuffix(units), precipSuffix(units)
+	return csvSeries{
+		rows: func(d forecast.WeatherData) int { return len(d.Hourly.Time) },
+		columns: []csvColumn{
+			{"time", "time", func(s Section, zone *time.Location, i int) string {
+				return offsetTime(s.Weather.Hourly.Time[i], zone)
+			}},
+			{"temperature" + temp, "temperature", func(s Section, _ *time.Location, i int) string {
+				return formatFloat(s.Weather.Hourly.Temperature[i])
+			}},
+			{"precipitation" + precip, "precipitation", func(s Section, _ *time.Location, i int) string {
+				return formatFloat(s.Weather.Hourly.Precipitation[i])
+			}},
+			{"weather_code", "weather_code", func(s Section, _ *time.Location, i int) string {
+				return strconv.Itoa(s.Weather.Hourly.WeatherCode[i])
+			}},
+			{"condition", "condition", func(s Section, _ *time.Location, i int) string {
+				return WeatherDescription(s.Weather.Hourly.WeatherCode[i])
+			}},
+		},
+	}
+}
+
+// currentSeries lists the columns of the current conditions
+func currentSeries(units forecast.UnitSystem) csvSeries {
+	return csvSeries{
+		rows: func(forecast.WeatherData) int { return 1 },
+		columns: []csvColumn{
+			{"time", "time", func(s Section, zone *time.Location, _ int) string {
+				return offsetTime(s.Weather.CurrentWeather.Time, zone)
+			}},
+			{"temperature" + tempSuffix(units), "temperature", func(s Section, _ *time.Location, _ int) string {
+				return formatFloat(s.Weather.CurrentWeather.Temperature)
+			}},
+			{"wind_speed" + windSuffix(units), "wind_speed", func(s Section, _ *time.Location, _ int) string {
+				return formatFloat(s.Weather.CurrentWeather.WindSpeed)
+			}},
+			{"weather_code", "weather_code", func(s Section, _ *time.Location, _ int) string {
+				return strconv.Itoa(s.Weather.CurrentWeather.WeatherCode)
+			}},
+			{"condition", "condition", func(s Section, _ *time.Location, _ int) string {
+				return WeatherDescription(s.Weather.CurrentWeather.WeatherCode)
+			}},
+		},
+	}
+}
+
+// selectColumns returns the columns named in names, in that order, or all
+// columns when names is empty. A name matches with or without its unit suffix.
+func selectColumns(columns []csvColumn, names []string) []csvColumn {
+	if len(names) == 0 {
+		return columns
+	}
+	var selected []csvColumn
+	for _, name := range names {
+		for _, c := range columns {
+			if name == c.name || name == c.base {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// checkColumns reports names that match no column of any series
+func checkColumns(series []csvSeries, names []string) error {
+	var known []string
+	for _, name := range names {
+		found := false
+		for _, s := range series {
+			if len(selectColumns(s.columns, []string{name})) > 0 {
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		for _, s := range series {
+			for _, c := range s.columns {
+				known = append(known, c.name)
+			}
+		}
+		return fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// sectionZone returns the location's time zone, or UTC when it is unknown
+func sectionZone(s Section) *time.Location {
+	return s.Location.Zone()
+}
+
+// offsetTime converts a model timestamp (UTC) to ISO 8601 local time with
+// an explicit offset, e.g. 2024-05-01T08:00:00-04:00
+func offsetTime(s string, zone *time.Location) string {
+	t, err := time.Parse("2006-01-02T15:04", s)
+	if err != nil {
+		return s
+	}
+	return t.In(zone).Format("2006-01-02T15:04:05-07:00")
+}
+
+// formatFloat formats a value, leaving the cell empty when it is missing
+func formatFloat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Column name suffixes for each unit
+func tempSuffix(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "_f"
+	}
+	return "_c"
+}
+
+func windSuffix(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "_mph"
+	}
+	return "_kmh"
+}
+
+func precipSuffix(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "_in"
+	}
+	return "_mm"
+}
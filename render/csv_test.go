@@ -0,0 +1,68 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestCSV(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.Daily.Time = []string{"2024-05-01"}
+	weather.Daily.WeatherCode = []int{61}
+	weather.Daily.TemperatureMin = []float64{9}
+	weather.Daily.TemperatureMax = []float64{19.5}
+	weather.Daily.PrecipitationSum = []float64{2.5}
+	weather.Hourly.Time = []string{"2024-05-01T12:00"}
+	weather.Hourly.Temperature = []float64{18}
+	weather.Hourly.Precipitation = []float64{0.2}
+	weather.Hourly.WeatherCode = []int{3}
+
+	utc := Section{Label: "a", Weather: weather}
+	tokyo := Section{Label: "b", Location: geocode.GeoLocation{Timezone: "Asia/Tokyo"}, Weather: weather}
+
+	tests := []struct {
+		name     string
+		sections []Section
+		opts     Options
+		comma    rune
+		want     string
+	}{
+		{
+			name:     "daily and hourly",
+			sections: []Section{utc},
+			opts:     Options{Daily: true, Hourly: true, Units: forecast.UnitMetric},
+			comma:    ',',
+			want: "date,weather_code,condition,temperature_min_c,temperature_max_c,precipitation_sum_mm\n" +
+				"2024-05-01,61,Slight rain,9,19.5,2.5\n" +
+				"\n" +
+				"time,temperature_c,precipitation_mm,weather_code,condition\n" +
+				"2024-05-01T12:00:00+00:00,18,0.2,3,Overcast\n",
+		},
+		{
+			name:     "columns and locations",
+			sections: []Section{utc, tokyo},
+			opts:     Options{Hourly: true, Units: forecast.UnitImperial, Columns: []string{"temperature", "time"}},
+			comma:    '\t',
+			want: "location\ttemperature_f\ttime\n" +
+				"a\t18\t2024-05-01T12:00:00+00:00\n" +
+				"b\t18\t2024-05-01T21:00:00+09:00\n",
+		},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := CSV(&out, tt.sections, tt.opts, tt.comma); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, out.String(), tt.want)
+		}
+	}
+
+	var out strings.Builder
+	if err := CSV(&out, []Section{utc}, Options{Columns: []string{"humidity"}}, ','); err == nil {
+		t.Error("unknown column accepted")
+	}
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxPoint is one line of line protocol
+type influxPoint struct {
+	measurement string
+	fields      []influxField
+	time        time.Time
+}
+
+// influxField is a field key and its already formatted value
+type influxField struct {
+	key   string
+	value string
+}
+
+// Influx writes the forecast in InfluxDB line protocol with nanosecond
+// timestamps: a weather_current point per location, plus one
+// weather_daily and weather_hourly point per forecast entry when those are
+// requested. Points are tagged with the location and unit system.
+func Influx(w io.Writer, sections []Section, opts Options) error {
+	for _, s := range sections {
+		tags := ",location=" + influxTag.Replace(s.Label) + ",units=" + influxTag.Replace(string(opts.Units))
+		for _, p := range influxPoints(s, opts) {
+			if _, err := fmt.Fprintf(w, "%s%s %s %d\n", p.measurement, tags, joinFields(p.fields), p.time.UnixNano()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// influxPoints converts one location's forecast to points, skipping entries
+// whose time cannot be parsed
+func influxPoints(s Section, opts Options) []influxPoint {
+	var points []influxPoint
+	w := s.Weather
+	if t, err := time.Parse("2006-01-02T15:04", w.CurrentWeather.Time); err == nil {
+		points = append(points, influxPoint{"weather_current", []influxField{
+			{"temperature", influxFloat(w.CurrentWeather.Temperature)},
+			{"wind_speed", influxFloat(w.CurrentWeather.WindSpeed)},
+			{"weather_code", influxInt(w.CurrentWeather.WeatherCode)},
+		}, t})
+	}
+
+	if opts.Daily {
+		zone := sectionZone(s)
+		for i, day := range w.Daily.Time {
+			t, err := time.ParseInLocation("2006-01-02", day, zone)
+			if err != nil {
+				continue
+			}
+			points = append(points, influxPoint{"weather_daily", []influxField{
+				{"temperature_max", influxFloat(w.Daily.TemperatureMax[i])},
+				{"temperature_min", influxFloat(w.Daily.TemperatureMin[i])},
+				{"precipitation_sum", influxFloat(w.Daily.PrecipitationSum[i])},
+				{"weather_code", influxInt(w.Daily.WeatherCode[i])},
+			}, t})
+		}
+	}
+
+	if opts.Hourly {
+		for i, hour := range w.Hourly.Time {
+			t, err := time.Parse("2006-01-02T15:04", hour)
+			if err != nil {
+				continue
+			}
+			points = append(points, influxPoint{"weather_hourly", []influxField{
+				{"temperature", influxFloat(w.Hourly.Temperature[i])},
+				{"precipitation", influxFloat(w.Hourly.Precipitation[i])},
+				{"weather_code", influxInt(w.Hourly.WeatherCode[i])},
+			}, t})
+		}
+	}
+	return points
+}
+
+// joinFields formats the field set, leaving out missing values
+func joinFields(fields []influxField) string {
+	var parts []string
+	for _, f := range fields {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// influxTag escapes tag values, which end at commas, equals signs and spaces
+var influxTag = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `)
+
+// influxFloat formats a float field, or "" to leave out a missing value
+func influxFloat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// influxInt formats an integer field, which line protocol marks with an i
+func influxInt(v int) string {
+	return strconv.Itoa(v) + "i"
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+// timeSeriesWeather has one point of each series at 2024-05-01 12:00 UTC
+func timeSeriesWeather() forecast.WeatherData {
+	var weather forecast.WeatherData
+	weather.CurrentWeather.Time = "2024-05-01T12:00"
+	weather.CurrentWeather.Temperature = 17.5
+	weather.CurrentWeather.WindSpeed = 12
+	weather.CurrentWeather.WeatherCode = 2
+	weather.Daily.Time = []string{"2024-05-01"}
+	weather.Daily.WeatherCode = []int{61}
+	weather.Daily.TemperatureMin = []float64{9}
+	weather.Daily.TemperatureMax = []float64{19.5}
+	weather.Daily.PrecipitationSum = []float64{2.5}
+	weather.Hourly.Time = []string{"2024-05-01T12:00"}
+	weather.Hourly.Temperature = []float64{18}
+	weather.Hourly.Precipitation = []float64{0.2}
+	weather.Hourly.WeatherCode = []int{3}
+	return weather
+}
+
+func TestInflux(t *testing.T) {
+	section := Section{
+		Label:    "New York, NY",
+		Location: geocode.GeoLocation{Timezone: "Asia/Tokyo"},
+		Weather:  timeSeriesWeather(),
+	}
+
+	var out strings.Builder
+	if err := Influx(&out, []Section{section}, Options{Daily: true, Hourly: true, Units: forecast.UnitMetric}); err != nil {
+		t.Fatal(err)
+	}
+	want := `weather_current,location=New\ York\,\ NY,units=metric temperature=17.5,wind_speed=12,weather_code=2i 1714564800000000000
+weather_daily,location=New\ York\,\ NY,units=metric temperature_max=19.5,temperature_min=9,precipitation_sum=2.5,weather_code=61i 1714489200000000000
+weather_hourly,location=New\ York\,\ NY,units=metric temperature=18,precipitation=0.2,weather_code=3i 1714564800000000000
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestOpenMetrics(t *testing.T) {
+	section := Section{Label: "home", Weather: timeSeriesWeather()}
+
+	var out strings.Builder
+	if err := OpenMetrics(&out, []Section{section}, Options{Hourly: true, Units: forecast.UnitImperial}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"# TYPE weather_temperature_fahrenheit gauge\n# UNIT weather_temperature_fahrenheit fahrenheit\n",
+		`weather_temperature_fahrenheit{location="home"} 17.5 1714564800` + "\n",
+		`weather_hourly_precipitation_inches{location="home"} 0.2 1714564800` + "\n",
+		`weather_code{location="home"} 2 1714564800` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "weather_daily") {
+		t.Errorf("daily families without Daily:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "# EOF\n") {
+		t.Errorf("output does not end with # EOF:\n%s", got)
+	}
+}
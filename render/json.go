@@ -0,0 +1,239 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// SchemaVersion is the version of the JSON report format. It changes only
+// when a field is renamed, removed or changes meaning; new fields may be
+// added without a bump.
+const SchemaVersion = 1
+
+// Report is the top level of the JSON output
+type Report struct {
+	SchemaVersion int              `json:"schema_version"`
+	GeneratedAt   string           `json:"generated_at"` // RFC 3339, UTC
+	Units         ReportUnits      `json:"units"`
+	Forecasts     []LocationReport `json:"forecasts"` // one per requested location
+}
+
+// ReportUnits names the unit of each measurement
+type ReportUnits struct {
+	System        forecast.UnitSystem `json:"system"`
+	Temperature   string              `json:"temperature"`
+	WindSpeed     string              `json:"wind_speed"`
+	Precipitation string              `json:"precipitation"`
+}
+
+// LocationReport is the forecast for one location
+type LocationReport struct {
+	Query     string           `json:"query"`
+	Location  ReportLocation   `json:"location"`
+	Source    string           `json:"source"`
+	Cached    bool             `json:"cached"`
+	Consensus *ReportConsensus `json:"consensus,omitempty"`
+	Current   ReportConditions `json:"current"`
+	Daily     []ReportDay      `json:"daily,omitempty"`
+	Hourly    []ReportHour     `json:"hourly,omitempty"`
+}
+
+// ReportLocation is the resolved place
+type ReportLocation struct {
+	Name        string  `json:"name"`
+	Admin1      string  `json:"admin1,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Elevation   float64 `json:"elevation,omitempty"`
+	Timezone    string  `json:"timezone,omitempty"`
+}
+
+// ReportConsensus describes a blended forecast; the *_range fields then give
+// the min/max across providers
+type ReportConsensus struct {
+	Method    string   `json:"method"`
+	Providers []string `json:"providers"`
+}
+
+// ReportConditions are the current conditions
+type ReportConditions struct {
+	Time             string          `json:"time"` // RFC 3339, UTC
+	Temperature      float64         `json:"temperature"`
+	WindSpeed        float64         `json:"wind_speed"`
+	WeatherCode      int             `json:"weather_code"` // WMO code
+	Condition        string          `json:"condition"`
+	TemperatureRange *forecast.Range `json:"temperature_range,omitempty"`
+	WindSpeedRange   *forecast.Range `json:"wind_speed_range,omitempty"`
+}
+
+// ReportDay is one day of the daily forecast. PrecipitationSum is NaN, and
+// null in JSON, when the provider does not report it.
+type ReportDay struct {
+	Date                  string          `json:"date"` // YYYY-MM-DD
+	WeatherCode           int             `json:"weather_code"`
+	Condition             string          `json:"condition"`
+	TemperatureMin        float64         `json:"temperature_min"`
+	TemperatureMax        float64         `json:"temperature_max"`
+	PrecipitationSum      float64         `json:"precipitation_sum"`
+	TemperatureMinRange   *forecast.Range `json:"temperature_min_range,omitempty"`
+	TemperatureMaxRange   *forecast.Range `json:"temperature_max_range,omitempty"`
+	PrecipitationSumRange *forecast.Range `json:"precipitation_sum_range,omitempty"`
+}
+
+// ReportHour is one hour of the hourly forecast. Precipitation is NaN, and
+// null in JSON, when the provider does not report it.
+type ReportHour struct {
+	Time               string          `json:"time"` // RFC 3339, UTC
+	Temperature        float64         `json:"temperature"`
+	Precipitation      float64         `json:"precipitation"`
+	WeatherCode        int             `json:"weather_code"`
+	Condition          string          `json:"condition"`
+	TemperatureRange   *forecast.Range `json:"temperature_range,omitempty"`
+	PrecipitationRange *forecast.Range `json:"precipitation_range,omitempty"`
+}
+
+// JSON writes the sections as an indented Report
+func JSON(w io.Writer, sections []Section, opts Options) error {
+	report := NewReport(sections, opts)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// NewReport converts sections into the JSON report model
+func NewReport(sections []Section, opts Options) Report {
+	report := Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Units: ReportUnits{
+			System:        opts.Units,
+			Temperature:   forecast.TempUnit(opts.Units),
+			WindSpeed:     forecast.WindUnit(opts.Units),
+			Precipitation: forecast.PrecipUnit(opts.Units),
+		},
+		Forecasts: []LocationReport{},
+	}
+	for _, s := range sections {
+		report.Forecasts = append(report.Forecasts, locationReport(s, opts))
+	}
+	return report
+}
+
+// locationReport converts one section
+func locationReport(s Section, opts Options) LocationReport {
+	weather := s.Weather
+	spread := weather.Spread
+	loc := s.Location
+
+	r := LocationReport{
+		Query: s.Label,
+		Location: ReportLocation{
+			Name:        loc.Name,
+			Admin1:      loc.Admin1,
+			Country:     loc.Country,
+			CountryCode: loc.CountryCode,
+			Latitude:    loc.Latitude,
+			Longitude:   loc.Longitude,
+			Elevation:   loc.Elevation,
+			Timezone:    loc.Timezone,
+		},
+		Source: weather.Source,
+		Cached: s.Cached,
+		Current: ReportConditions{
+			Time:        isoTime(weather.CurrentWeather.Time),
+			Temperature: weather.CurrentWeather.Temperature,
+			WindSpeed:   weather.CurrentWeather.WindSpeed,
+			WeatherCode: weather.CurrentWeather.WeatherCode,
+			Condition:   WeatherDescription(weather.CurrentWeather.WeatherCode),
+		},
+	}
+	if spread != nil {
+		r.Consensus = &ReportConsensus{Method: spread.Method, Providers: spread.Providers}
+		r.Current.TemperatureRange = &spread.Current.Temperature
+		r.Current.WindSpeedRange = &spread.Current.WindSpeed
+	}
+
+	if opts.Daily {
+		r.Daily = []ReportDay{}
+		for i, day := range weather.Daily.Time {
+			d := ReportDay{
+				Date:             day,
+				WeatherCode:      weather.Daily.WeatherCode[i],
+				Condition:        WeatherDescription(weather.Daily.WeatherCode[i]),
+				TemperatureMin:   weather.Daily.TemperatureMin[i],
+				TemperatureMax:   weather.Daily.TemperatureMax[i],
+				PrecipitationSum: weather.Daily.PrecipitationSum[i],
+			}
+			if spread != nil && i < len(spread.Daily.TemperatureMax) {
+				d.TemperatureMinRange = &spread.Daily.TemperatureMin[i]
+				d.TemperatureMaxRange = &spread.Daily.TemperatureMax[i]
+				if !spread.Daily.PrecipitationSum[i].Missing() {
+					d.PrecipitationSumRange = &spread.Daily.PrecipitationSum[i]
+				}
+			}
+			r.Daily = append(r.Daily, d)
+		}
+	}
+
+	if opts.Hourly {
+		r.Hourly = []ReportHour{}
+		for i, t := range weather.Hourly.Time {
+			h := ReportHour{
+				Time:          isoTime(t),
+				Temperature:   weather.Hourly.Temperature[i],
+				Precipitation: weather.Hourly.Precipitation[i],
+				WeatherCode:   weather.Hourly.WeatherCode[i],
+				Condition:     WeatherDescription(weather.Hourly.WeatherCode[i]),
+			}
+			if spread != nil && i < len(spread.Hourly.Temperature) {
+				h.TemperatureRange = &spread.Hourly.Temperature[i]
+				if !spread.Hourly.Precipitation[i].Missing() {
+					h.PrecipitationRange = &spread.Hourly.Precipitation[i]
+				}
+			}
+			r.Hourly = append(r.Hourly, h)
+		}
+	}
+	return r
+}
+
+// MarshalJSON implements json.Marshaler, writing a missing amount as null
+func (d ReportDay) MarshalJSON() ([]byte, error) {
+	type plain ReportDay
+	return json.Marshal(struct {
+		plain
+		PrecipitationSum *float64 `json:"precipitation_sum"`
+	}{plain(d), nullable(d.PrecipitationSum)})
+}
+
+// MarshalJSON implements json.Marshaler, writing a missing amount as null
+func (h ReportHour) MarshalJSON() ([]byte, error) {
+	type plain ReportHour
+	return json.Marshal(struct {
+		plain
+		Precipitation *float64 `json:"precipitation"`
+	}{plain(h), nullable(h.Precipitation)})
+}
+
+// nullable returns nil for a missing (NaN) value
+func nullable(v float64) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// isoTime converts a model timestamp (UTC, minute precision) to RFC 3339
+func isoTime(s string) string {
+	t, err := time.Parse("2006-01-02T15:04", s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
+}
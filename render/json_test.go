@@ -0,0 +1,85 @@
+package render
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestJSON(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.Source = "open-meteo+nws"
+	weather.CurrentWeather.Time = "2024-05-01T12:00"
+	weather.CurrentWeather.Temperature = 18.5
+	weather.CurrentWeather.WeatherCode = 61
+	weather.Daily.Time = []string{"2024-05-01"}
+	weather.Daily.WeatherCode = []int{3}
+	weather.Daily.TemperatureMin = []float64{9}
+	weather.Daily.TemperatureMax = []float64{19}
+	weather.Daily.PrecipitationSum = []float64{2.5}
+	weather.Hourly.Time = []string{"2024-05-01T13:00"}
+	weather.Hourly.Temperature = []float64{19}
+	weather.Hourly.Precipitation = []float64{0}
+	weather.Hourly.WeatherCode = []int{0}
+	weather.Spread = &forecast.Spread{Method: forecast.ConsensusMedian, Providers: []string{"open-meteo", "nws"}}
+	weather.Spread.Current.Temperature = forecast.Range{Min: 17, Max: 20}
+	weather.Spread.Daily.TemperatureMin = []forecast.Range{{Min: 8, Max: 10}}
+	weather.Spread.Daily.TemperatureMax = []forecast.Range{{Min: 18, Max: 20}}
+	weather.Spread.Daily.PrecipitationSum = []forecast.Range{{Min: 1, Max: 4}}
+
+	sections := []Section{{
+		Label:    "nyc",
+		Location: geocode.GeoLocation{Name: "New York", Latitude: 40.71, Longitude: -74.01, Timezone: "America/New_York"},
+		Weather:  weather,
+	}}
+
+	var out strings.Builder
+	if err := JSON(&out, sections, Options{Daily: true, Units: forecast.UnitMetric}); err != nil {
+		t.Fatal(err)
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if report.SchemaVersion != SchemaVersion || report.Units.Temperature != "°C" {
+		t.Errorf("header = %+v", report)
+	}
+	f := report.Forecasts[0]
+	if f.Query != "nyc" || f.Location.Timezone != "America/New_York" {
+		t.Errorf("location = %+v", f.Location)
+	}
+	if f.Current.Time != "2024-05-01T12:00:00Z" || f.Current.Condition != "Slight rain" {
+		t.Errorf("current = %+v", f.Current)
+	}
+	if len(f.Daily) != 1 || f.Daily[0].PrecipitationSumRange == nil || f.Daily[0].PrecipitationSumRange.Max != 4 {
+		t.Errorf("daily = %+v", f.Daily)
+	}
+	if f.Consensus == nil || f.Current.TemperatureRange.Min != 17 {
+		t.Errorf("consensus spread missing: %+v", f)
+	}
+	if f.Hourly != nil {
+		t.Errorf("hourly included without -hourly: %+v", f.Hourly)
+	}
+}
+
+func TestJSONMissingPrecipitation(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.Daily.Time = []string{"2024-05-01"}
+	weather.Daily.WeatherCode = []int{3}
+	weather.Daily.TemperatureMin = []float64{9}
+	weather.Daily.TemperatureMax = []float64{19}
+	weather.Daily.PrecipitationSum = []float64{math.NaN()}
+
+	var out strings.Builder
+	if err := JSON(&out, []Section{{Label: "nyc", Weather: weather}}, Options{Daily: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"precipitation_sum": null`) {
+		t.Errorf("missing precipitation not written as null:\n%s", out.String())
+	}
+}
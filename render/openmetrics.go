@@ -0,0 +1,173 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// omFamily is one OpenMetrics gauge family
+type omFamily struct {
+	name   string
+	unit   string // empty for unitless values
+	help   string
+	points func(s Section) []omPoint
+}
+
+// omPoint is a value and the time it applies to
+type omPoint struct {
+	value float64
+	time  time.Time
+}
+
+// OpenMetrics writes the forecast as OpenMetrics text with one timestamped
+// gauge sample per value, labeled by location, e.g. for backfilling with
+// promtool. Daily and hourly families are included when requested.
+func OpenMetrics(w io.Writer, sections []Section, opts Options) error {
+	families := currentFamilies(opts.Units)
+	if opts.Daily {
+		families = append(families, dailyFamilies(opts.Units)...)
+	}
+	if opts.Hourly {
+		families = append(families, hourlyFamilies(opts.Units)...)
+	}
+
+	var b strings.Builder
+	for _, f := range families {
+		name := f.name
+		if f.unit != "" {
+			name += "_" + f.unit
+		}
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", name)
+		if f.unit != "" {
+			fmt.Fprintf(&b, "# UNIT %s %s\n", name, f.unit)
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, f.help)
+		for _, s := range sections {
+			for _, p := range f.points(s) {
+				fmt.Fprintf(&b, "%s{location=\"%s\"} %s %d\n", name, omLabel.Replace(s.Label),
+					strconv.FormatFloat(p.value, 'f', -1, 64), p.time.Unix())
+			}
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func currentFamilies(units forecast.UnitSystem) []omFamily {
+	current := func(value func(w forecast.WeatherData) float64) func(Section) []omPoint {
+		return func(s Section) []omPoint {
+			t, err := time.Parse("2006-01-02T15:04", s.Weather.CurrentWeather.Time)
+			if err != nil {
+				return nil
+			}
+			return []omPoint{{value(s.Weather), t}}
+		}
+	}
+	return []omFamily{
+		{"weather_temperature", omTempUnit(units), "Current air temperature.", current(func(w forecast.WeatherData) float64 {
+			return w.CurrentWeather.Temperature
+		})},
+		{"weather_wind_speed", omWindUnit(units), "Current wind speed.", current(func(w forecast.WeatherData) float64 {
+			return w.CurrentWeather.WindSpeed
+		})},
+		{"weather_code", "", "Current WMO weather code.", current(func(w forecast.WeatherData) float64 {
+			return float64(w.CurrentWeather.WeatherCode)
+		})},
+	}
+}
+
+func dailyFamilies(units forecast.UnitSystem) []omFamily {
+	daily := func(values func(w forecast.WeatherData) []float64) func(Section) []omPoint {
+		return func(s Section) []omPoint {
+			var points []omPoint
+			zone := sectionZone(s)
+			v := values(s.Weather)
+			for i, day := range s.Weather.Daily.Time {
+				if t, err := time.ParseInLocation("2006-01-02", day, zone); err == nil && i < len(v) && !math.IsNaN(v[i]) {
+					points = append(points, omPoint{v[i], t})
+				}
+			}
+			return points
+		}
+	}
+	return []omFamily{
+		{"weather_daily_temperature_max", omTempUnit(units), "Forecast high temperature for the day.", daily(func(w forecast.WeatherData) []float64 {
+			return w.Daily.TemperatureMax
+		})},
+		{"weather_daily_temperature_min", omTempUnit(units), "Forecast low temperature for the day.", daily(func(w forecast.WeatherData) []float64 {
+			return w.Daily.TemperatureMin
+		})},
+		{"weather_daily_precipitation_sum", omPrecipUnit(units), "Forecast precipitation for the day.", daily(func(w forecast.WeatherData) []float64 {
+			return w.Daily.PrecipitationSum
+		})},
+		{"weather_daily_code", "", "Forecast WMO weather code for the day.", daily(func(w forecast.WeatherData) []float64 {
+			return intsToFloats(w.Daily.WeatherCode)
+		})},
+	}
+}
+
+func hourlyFamilies(units forecast.UnitSystem) []omFamily {
+	hourly := func(values func(w forecast.WeatherData) []float64) func(Section) []omPoint {
+		return func(s Section) []omPoint {
+			var points []omPoint
+			v := values(s.Weather)
+			for i, hour := range s.Weather.Hourly.Time {
+				if t, err := time.Parse("2006-01-02T15:04", hour); err == nil && i < len(v) && !math.IsNaN(v[i]) {
+					points = append(points, omPoint{v[i], t})
+				}
+			}
+			return points
+		}
+	}
+	return []omFamily{
+		{"weather_hourly_temperature", omTempUnit(units), "Forecast temperature for the hour.", hourly(func(w forecast.WeatherData) []float64 {
+			return w.Hourly.Temperature
+		})},
+		{"weather_hourly_precipitation", omPrecipUnit(units), "Forecast precipitation for the hour.", hourly(func(w forecast.WeatherData) []float64 {
+			return w.Hourly.Precipitation
+		})},
+		{"weather_hourly_code", "", "Forecast WMO weather code for the hour.", hourly(func(w forecast.WeatherData) []float64 {
+			return intsToFloats(w.Hourly.WeatherCode)
+		})},
+	}
+}
+
+func intsToFloats(ints []int) []float64 {
+	out := make([]float64, len(ints))
+	for i, v := range ints {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// omLabel escapes label values
+var omLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// OpenMetrics unit names for each unit system
+func omTempUnit(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "fahrenheit"
+	}
+	return "celsius"
+}
+
+func omWindUnit(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "miles_per_hour"
+	}
+	return "kilometers_per_hour"
+}
+
+func omPrecipUnit(units forecast.UnitSystem) string {
+	if units == forecast.UnitImperial {
+		return "inches"
+	}
+	return "millimeters"
+}
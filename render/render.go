@@ -0,0 +1,287 @@
+// Package render formats weather data for the terminal.
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// Options controls which sections are shown and how
+type Options struct {
+	Daily  bool
+	Hourly bool
+	Units  forecast.UnitSystem
+	Colors bool
+	Zone   *time.Location // zone of the daily dates, for finding today; nil means UTC
+
+	Columns []string // CSV/TSV columns to write, in order; empty means all
+}
+
+// today returns the current date in the zone of the daily dates
+func (o Options) today() string {
+	zone := o.Zone
+	if zone == nil {
+		zone = time.UTC
+	}
+	return time.Now().In(zone).Format("2006-01-02")
+}
+
+// Text writes weather in the plain text format
+func Text(w io.Writer, weather forecast.WeatherData, opts Options) {
+	unitSystem := opts.Units
+	tempUnit := forecast.TempUnit(unitSystem)
+	windUnit := forecast.WindUnit(unitSystem)
+	precipUnit := forecast.PrecipUnit(unitSystem)
+
+	spread := weather.Spread
+	if spread != nil {
+		fmt.Fprintf(w, "Consensus (%s) of %s\n\n", spread.Method, strings.Join(spread.Providers, ", "))
+	}
+
+	fmt.Fprintln(w, "Current Weather:")
+	if opts.Colors {
+		fmt.Fprintf(w, "  Temperature: %s%s\n", ColorizeTemp(weather.CurrentWeather.Temperature, unitSystem),
+			spreadNote(spread, func(s *forecast.Spread) string { return "range " + formatRange(s.Current.Temperature) }))
+	} else {
+		fmt.Fprintf(w, "  Temperature: %.1f%s%s\n", weather.CurrentWeather.Temperature, tempUnit,
+			spreadNote(spread, func(s *forecast.Spread) string { return "range " + formatRange(s.Current.Temperature) }))
+	}
+
+	// Add high/low temperatures for today if daily data is available
+	if len(weather.Daily.Time) > 0 {
+		today := opts.today()
+		for i, day := range weather.Daily.Time {
+			if day == today {
+				if opts.Colors {
+					fmt.Fprintf(w, "  High/Low: %s/%s\n",
+						ColorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
+						ColorizeTemp(weather.Daily.TemperatureMin[i], unitSystem))
+				} else {
+					fmt.Fprintf(w, "  High/Low: %.1f%s/%.1f%s\n",
+						weather.Daily.TemperatureMax[i], tempUnit,
+						weather.Daily.TemperatureMin[i], tempUnit)
+				}
+				break
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "  Wind Speed: %.1f %s%s\n", weather.CurrentWeather.WindSpeed, windUnit,
+		spreadNote(spread, func(s *forecast.Spread) string { return "range " + formatRange(s.Current.WindSpeed) }))
+	fmt.Fprintf(w, "  Time: %s\n", formatTime(weather.CurrentWeather.Time))
+	fmt.Fprintf(w, "  Weather: %s\n", WeatherDescription(weather.CurrentWeather.WeatherCode))
+
+	// Display daily forecast if requested
+	if opts.Daily && len(weather.Daily.Time) > 0 {
+		fmt.Fprintln(w, "\n7-Day Forecast:")
+		for i, day := range weather.Daily.Time {
+			t, _ := time.Parse("2006-01-02", day)
+			note := spreadNote(spread, func(s *forecast.Spread) string {
+				return fmt.Sprintf("low %s, high %s, precip %s",
+					formatRange(s.Daily.TemperatureMin[i]),
+					formatRange(s.Daily.TemperatureMax[i]),
+					formatRange(s.Daily.PrecipitationSum[i]))
+			})
+
+			if opts.Colors {
+				fmt.Fprintf(w, "  %s: %s, %s to %s, Precipitation: %s%s\n",
+					t.Format("Mon Jan 2"),
+					WeatherDescription(weather.Daily.WeatherCode[i]),
+					ColorizeTemp(weather.Daily.TemperatureMin[i], unitSystem),
+					ColorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
+					FormatPrecip(weather.Daily.PrecipitationSum[i], precipUnit), note)
+			} else {
+				fmt.Fprintf(w, "  %s: %s, %.1f%s to %.1f%s, Precipitation: %s%s\n",
+					t.Format("Mon Jan 2"),
+					WeatherDescription(weather.Daily.WeatherCode[i]),
+					weather.Daily.TemperatureMin[i], tempUnit,
+					weather.Daily.TemperatureMax[i], tempUnit,
+					FormatPrecip(weather.Daily.PrecipitationSum[i], precipUnit), note)
+			}
+		}
+	}
+
+	// Display hourly forecast if requested
+	if opts.Hourly && len(weather.Hourly.Time) > 0 {
+		fmt.Fprintln(w, "\nHourly Forecast (next 24h):")
+		for i := 0; i < 24 && i < len(weather.Hourly.Time); i++ {
+			t, _ := time.Parse("2006-01-02T15:04", weather.Hourly.Time[i])
+			note := spreadNote(spread, func(s *forecast.Spread) string {
+				return fmt.Sprintf("temp %s, precip %s",
+					formatRange(s.Hourly.Temperature[i]),
+					formatRange(s.Hourly.Precipitation[i]))
+			})
+
+			if opts.Colors {
+				fmt.Fprintf(w, "  %s: %s, %s, Precipitation: %s%s\n",
+					t.Format("15:04"),
+					WeatherDescription(weather.Hourly.WeatherCode[i]),
+					ColorizeTemp(weather.Hourly.Temperature[i], unitSystem),
+					FormatPrecip(weather.Hourly.Precipitation[i], precipUnit), note)
+			} else {
+				fmt.Fprintf(w, "  %s: %s, %.1f%s, Precipitation: %s%s\n",
+					t.Format("15:04"),
+					WeatherDescription(weather.Hourly.WeatherCode[i]),
+					weather.Hourly.Temperature[i], tempUnit,
+					FormatPrecip(weather.Hourly.Precipitation[i], precipUnit), note)
+			}
+		}
+	}
+}
+
+// FormatPrecip formats a precipitation amount with its unit, or "n/a" when
+// the provider does not report one
+func FormatPrecip(v float64, unit string) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%s", v, unit)
+}
+
+// precipCell formats a precipitation amount for a table column
+func precipCell(v float64, unit string) string {
+	if math.IsNaN(v) {
+		return fmt.Sprintf("%-15s%*s", "n/a", len(unit), "")
+	}
+	return fmt.Sprintf("%-15.1f%s", v, unit)
+}
+
+// Table writes weather in the table format
+func Table(w io.Writer, weather forecast.WeatherData, opts Options) {
+	unitSystem := opts.Units
+	tempUnit := forecast.TempUnit(unitSystem)
+	windUnit := forecast.WindUnit(unitSystem)
+	precipUnit := forecast.PrecipUnit(unitSystem)
+
+	// Current weather display
+	fmt.Fprintln(w, "Current Weather:")
+	printLine(w, 60) // Increased width to accommodate high/low
+	fmt.Fprintf(w, "| %-10s | %-12s | %-10s | %-12s | %-15s |\n", "Temperature", "High/Low", "Wind", "Time", "Condition")
+	printLine(w, 60)
+
+	// Find today's high/low if available
+	highTemp, lowTemp := weather.CurrentWeather.Temperature, weather.CurrentWeather.Temperature
+	if len(weather.Daily.Time) > 0 {
+		today := opts.today()
+		for i, day := range weather.Daily.Time {
+			if day == today {
+				highTemp = weather.Daily.TemperatureMax[i]
+				lowTemp = weather.Daily.TemperatureMin[i]
+				break
+			}
+		}
+	}
+
+	if opts.Colors {
+		fmt.Fprintf(w, "| %-10s | %-12s | %-10.1f %s | %-12s | %-15s |\n",
+			ColorizeTemp(weather.CurrentWeather.Temperature, unitSystem),
+			fmt.Sprintf("%s/%s",
+				ColorizeTemp(highTemp, unitSystem),
+				ColorizeTemp(lowTemp, unitSystem)),
+			weather.CurrentWeather.WindSpeed, windUnit,
+			formatTime(weather.CurrentWeather.Time),
+			truncateString(WeatherDescription(weather.CurrentWeather.WeatherCode), 15))
+	} else {
+		fmt.Fprintf(w, "| %-10.1f%s | %-12s | %-10.1f %s | %-12s | %-15s |\n",
+			weather.CurrentWeather.Temperature, tempUnit,
+			fmt.Sprintf("%.1f/%.1f%s", highTemp, lowTemp, tempUnit),
+			weather.CurrentWeather.WindSpeed, windUnit,
+			formatTime(weather.CurrentWeather.Time),
+			truncateString(WeatherDescription(weather.CurrentWeather.WeatherCode), 15))
+	}
+	printLine(w, 60)
+
+	// Display daily forecast if requested
+	if opts.Daily && len(weather.Daily.Time) > 0 {
+		fmt.Fprintln(w, "\n7-Day Forecast:")
+		printLine(w, 80)
+		fmt.Fprintf(w, "| %-10s | %-15s | %-12s | %-12s | %-15s |\n",
+			"Date", "Condition", "Min Temp", "Max Temp", "Precipitation")
+		printLine(w, 80)
+
+		for i, day := range weather.Daily.Time {
+			t, _ := time.Parse("2006-01-02", day)
+
+			if opts.Colors {
+				fmt.Fprintf(w, "| %-10s | %-15s | %-12s | %-12s | %s |\n",
+					t.Format("Mon Jan 2"),
+					truncateString(WeatherDescription(weather.Daily.WeatherCode[i]), 15),
+					ColorizeTemp(weather.Daily.TemperatureMin[i], unitSystem),
+					ColorizeTemp(weather.Daily.TemperatureMax[i], unitSystem),
+					precipCell(weather.Daily.PrecipitationSum[i], precipUnit))
+			} else {
+				fmt.Fprintf(w, "| %-10s | %-15s | %-12.1f%s | %-12.1f%s | %s |\n",
+					t.Format("Mon Jan 2"),
+					truncateString(WeatherDescription(weather.Daily.WeatherCode[i]), 15),
+					weather.Daily.TemperatureMin[i], tempUnit,
+					weather.Daily.TemperatureMax[i], tempUnit,
+					precipCell(weather.Daily.PrecipitationSum[i], precipUnit))
+			}
+		}
+		printLine(w, 80)
+	}
+
+	// Display hourly forecast if requested
+	if opts.Hourly && len(weather.Hourly.Time) > 0 {
+		fmt.Fprintln(w, "\nHourly Forecast (next 24h):")
+		printLine(w, 60)
+		fmt.Fprintf(w, "| %-5s | %-15s | %-12s | %-15s |\n",
+			"Time", "Condition", "Temperature", "Precipitation")
+		printLine(w, 60)
+
+		for i := 0; i < 24 && i < len(weather.Hourly.Time); i++ {
+			t, _ := time.Parse("2006-01-02T15:04", weather.Hourly.Time[i])
+
+			if opts.Colors {
+				fmt.Fprintf(w, "| %-5s | %-15s | %-12s | %s |\n",
+					t.Format("15:04"),
+					truncateString(WeatherDescription(weather.Hourly.WeatherCode[i]), 15),
+					ColorizeTemp(weather.Hourly.Temperature[i], unitSystem),
+					precipCell(weather.Hourly.Precipitation[i], precipUnit))
+			} else {
+				fmt.Fprintf(w, "| %-5s | %-15s | %-12.1f%s | %s |\n",
+					t.Format("15:04"),
+					truncateString(WeatherDescription(weather.Hourly.WeatherCode[i]), 15),
+					weather.Hourly.Temperature[i], tempUnit,
+					precipCell(weather.Hourly.Precipitation[i], precipUnit))
+			}
+		}
+		printLine(w, 60)
+	}
+
+	if weather.Spread != nil {
+		spreadTable(w, weather, opts)
+	}
+}
+
+// Helper function to print a horizontal line for tables
+func printLine(w io.Writer, width int) {
+	fmt.Fprint(w, "+")
+	for i := 0; i < width-2; i++ {
+		fmt.Fprint(w, "-")
+	}
+	fmt.Fprintln(w, "+")
+}
+
+// Helper function to truncate strings to fit in table cells
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
+
+// Helper function to format ISO time string
+func formatTime(timeStr string) string {
+	// Parse time and format it to a more readable form
+	t, err := time.Parse("2006-01-02T15:04", timeStr)
+	if err != nil {
+		return timeStr
+	}
+	return t.Format("15:04")
+}
@@ -0,0 +1,15 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+func TestColorizeTemp(t *testing.T) {
+	// Simple test just to check it doesn't crash
+	result := ColorizeTemp(20.0, forecast.UnitMetric)
+	if result == "" {
+		t.Error("ColorizeTemp returned empty string")
+	}
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// spreadNote returns " [detail]" for consensus forecasts and "" otherwise
+func spreadNote(spread *forecast.Spread, detail func(*forecast.Spread) string) string {
+	if spread == nil {
+		return ""
+	}
+	return " [" + detail(spread) + "]"
+}
+
+// formatRange formats a provider range as "min to max"
+func formatRange(r forecast.Range) string {
+	if r.Missing() {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f to %.1f", r.Min, r.Max)
+}
+
+// spreadTable writes the min/max across providers for each blended value
+func spreadTable(w io.Writer, weather forecast.WeatherData, opts Options) {
+	spread := weather.Spread
+	tempUnit := forecast.TempUnit(opts.Units)
+	windUnit := forecast.WindUnit(opts.Units)
+	precipUnit := forecast.PrecipUnit(opts.Units)
+
+	fmt.Fprintf(w, "\nProvider Spread (%s of %s):\n", spread.Method, strings.Join(spread.Providers, ", "))
+	printLine(w, 60)
+	fmt.Fprintf(w, "| %-10s | %-20s | %-20s |\n", "", "Temperature", "Wind")
+	printLine(w, 60)
+	fmt.Fprintf(w, "| %-10s | %-20s | %-20s |\n", "Current",
+		formatRange(spread.Current.Temperature)+tempUnit,
+		formatRange(spread.Current.WindSpeed)+" "+windUnit)
+	printLine(w, 60)
+
+	if opts.Daily && len(spread.Daily.TemperatureMax) > 0 {
+		fmt.Fprintln(w)
+		printLine(w, 80)
+		fmt.Fprintf(w, "| %-10s | %-20s | %-20s | %-20s |\n", "Date", "Min Temp", "Max Temp", "Precipitation")
+		printLine(w, 80)
+		for i, day := range weather.Daily.Time {
+			t, _ := time.Parse("2006-01-02", day)
+			fmt.Fprintf(w, "| %-10s | %-20s | %-20s | %-20s |\n",
+				t.Format("Mon Jan 2"),
+				formatRange(spread.Daily.TemperatureMin[i])+tempUnit,
+				formatRange(spread.Daily.TemperatureMax[i])+tempUnit,
+				formatRange(spread.Daily.PrecipitationSum[i])+precipUnit)
+		}
+		printLine(w, 80)
+	}
+
+	if opts.Hourly && len(spread.Hourly.Temperature) > 0 {
+		fmt.Fprintln(w)
+		printLine(w, 60)
+		fmt.Fprintf(w, "| %-5s | %-20s | %-20s |\n", "Time", "Temperature", "Precipitation")
+		printLine(w, 60)
+		for i := 0; i < 24 && i < len(weather.Hourly.Time); i++ {
+			fmt.Fprintf(w, "| %-5s | %-20s | %-20s |\n",
+				formatTime(weather.Hourly.Time[i]),
+				formatRange(spread.Hourly.Temperature[i])+tempUnit,
+				formatRange(spread.Hourly.Precipitation[i])+precipUnit)
+		}
+		printLine(w, 60)
+	}
+}
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// Status bar formats accepted by StatusBar
+const (
+	StatusWaybar   = "waybar"
+	StatusI3Blocks = "i3blocks"
+	StatusI3Bar    = "i3bar"
+	StatusPolybar  = "polybar"
+	StatusTmux     = "tmux"
+)
+
+// I3BarHeader starts the i3bar protocol stream: the version header and the
+// opening of the endless array. StatusBar then writes one element per update.
+func I3BarHeader(w io.Writer) error {
+	_, err := io.WriteString(w, "{\"version\":1}\n[\n")
+	return err
+}
+
+// tooltipHours is how many hours of the hourly forecast the tooltip shows
+const tooltipHours = 6
+
+// StatusBar writes a compact icon and temperature for a status bar. Several
+// sections are shown side by side, each labelled. Colors follow the
+// temperature when opts.Colors is set.
+func StatusBar(w io.Writer, sections []Section, opts Options, format string) error {
+	texts := make([]string, len(sections))
+	for i, s := range sections {
+		texts[i] = compactText(s.Weather, opts)
+		if len(sections) > 1 {
+			texts[i] += " " + s.Label
+		}
+	}
+	first := sections[0].Weather.CurrentWeather
+	color := TempColor(first.Temperature, opts.Units)
+
+	switch format {
+	case StatusWaybar:
+		tooltips := make([]string, len(sections))
+		for i, s := range sections {
+			tooltips[i] = Tooltip(s, opts)
+		}
+		return json.NewEncoder(w).Encode(struct {
+			Text    string `json:"text"`
+			Tooltip string `json:"tooltip"`
+			Class   string `json:"class"`
+			Alt     string `json:"alt"`
+		}{
+			Text:    strings.Join(texts, "  "),
+			Tooltip: strings.Join(tooltips, "\n\n"),
+			Class:   WeatherCategory(first.WeatherCode),
+			Alt:     WeatherDescription(first.WeatherCode),
+		})
+
+	case StatusI3Blocks:
+		// full_text, short_text and color, one per line
+		fmt.Fprintln(w, strings.Join(texts, " | "))
+		fmt.Fprintln(w, compactText(sections[0].Weather, opts))
+		if opts.Colors {
+			fmt.Fprintln(w, color)
+		}
+		return nil
+
+	case StatusI3Bar:
+		type block struct {
+			Name      string `json:"name"`
+			Instance  string `json:"instance"`
+			FullText  string `json:"full_text"`
+			ShortText string `json:"short_text"`
+			Color     string `json:"color,omitempty"`
+		}
+		blocks := make([]block, len(sections))
+		for i, s := range sections {
+			blocks[i] = block{Name: "weather", Instance: s.Label, FullText: texts[i], ShortText: compactText(s.Weather, opts)}
+			if opts.Colors {
+				blocks[i].Color = TempColor(s.Weather.CurrentWeather.Temperature, opts.Units)
+			}
+		}
+		// One element of the stream begun by I3BarHeader; the trailing comma
+		// leaves room for the next update
+		data, err := json.Marshal(blocks)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s,\n", data)
+		return err
+
+	case StatusPolybar:
+		for i, s := range sections {
+			if opts.Colors {
+				texts[i] = "%{F" + TempColor(s.Weather.CurrentWeather.Temperature, opts.Units) + "}" + texts[i] + "%{F-}"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(texts, "  "))
+		return nil
+
+	case StatusTmux:
+		for i, s := range sections {
+			if opts.Colors {
+				texts[i] = "#[fg=" + TempColor(s.Weather.CurrentWeather.Temperature, opts.Units) + "]" + texts[i] + "#[default]"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(texts, " "))
+		return nil
+	}
+	return fmt.Errorf("unknown status bar format %q", format)
+}
+
+// compactText is the icon and rounded temperature, e.g. "⛅ 18°C"
+func compactText(weather forecast.WeatherData, opts Options) string {
+	return fmt.Sprintf("%s %.0f%s", WeatherIcon(weather.CurrentWeather.WeatherCode),
+		weather.CurrentWeather.Temperature, forecast.TempUnit(opts.Units))
+}
+
+// Tooltip describes the location, current conditions and the coming hours
+// and days in a few lines
+func Tooltip(s Section, opts Options) string {
+	weather := s.Weather
+	zone := sectionZone(s)
+	tempUnit := forecast.TempUnit(opts.Units)
+	precipUnit := forecast.PrecipUnit(opts.Units)
+	current := weather.CurrentWeather
+
+	var b strings.Builder
+	name := s.Location.String()
+	if name == "" {
+		name = s.Label
+	}
+	fmt.Fprintf(&b, "%s\n%s, %.1f%s, wind %.1f %s", name, WeatherDescription(current.WeatherCode),
+		current.Temperature, tempUnit, current.WindSpeed, forecast.WindUnit(opts.Units))
+
+	shown := 0
+	for i, t := range weather.Hourly.Time {
+		if shown == tooltipHours {
+			break
+		}
+		if t < current.Time {
+			continue
+		}
+		if shown == 0 {
+			b.WriteString("\n")
+		}
+		shown++
+		fmt.Fprintf(&b, "\n%s  %s %.0f%s  %s", localClock(t, zone), WeatherIcon(weather.Hourly.WeatherCode[i]),
+			weather.Hourly.Temperature[i], tempUnit, FormatPrecip(weather.Hourly.Precipitation[i], precipUnit))
+	}
+
+	for i, day := range weather.Daily.Time {
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		d, _ := time.Parse("2006-01-02", day)
+		fmt.Fprintf(&b, "\n%s  %s %.0f/%.0f%s  %s", d.Format("Mon"), WeatherIcon(weather.Daily.WeatherCode[i]),
+			weather.Daily.TemperatureMin[i], weather.Daily.TemperatureMax[i], tempUnit,
+			FormatPrecip(weather.Daily.PrecipitationSum[i], precipUnit))
+	}
+	return b.String()
+}
+
+// localClock formats a model timestamp (UTC) as "15:04" in zone
+func localClock(s string, zone *time.Location) string {
+	t, err := time.Parse("2006-01-02T15:04", s)
+	if err != nil {
+		return s
+	}
+	return t.In(zone).Format("15:04")
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestStatusBar(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.CurrentWeather.Time = "2024-05-01T12:00"
+	weather.CurrentWeather.Temperature = 18.4
+	weather.CurrentWeather.WeatherCode = 61
+	weather.Hourly.Time = []string{"2024-05-01T11:00", "2024-05-01T12:00", "2024-05-01T13:00"}
+	weather.Hourly.Temperature = []float64{17, 18, 19}
+	weather.Hourly.Precipitation = []float64{0, 0.5, 1}
+	weather.Hourly.WeatherCode = []int{3, 61, 61}
+	weather.Daily.Time = []string{"2024-05-01"}
+	weather.Daily.WeatherCode = []int{61}
+	weather.Daily.TemperatureMin = []float64{9}
+	weather.Daily.TemperatureMax = []float64{19}
+	weather.Daily.PrecipitationSum = []float64{2.5}
+	sections := []Section{{Label: "home", Location: geocode.GeoLocation{Name: "Oslo", Country: "Norway"}, Weather: weather}}
+	opts := Options{Units: forecast.UnitMetric, Colors: true}
+
+	tests := map[string]string{
+		StatusI3Blocks: "🌧 18°C\n🌧 18°C\n#87d75f\n",
+		StatusPolybar:  "%{F#87d75f}🌧 18°C%{F-}\n",
+		StatusTmux:     "#[fg=#87d75f]🌧 18°C#[default]\n",
+		StatusI3Bar:    `[{"name":"weather","instance":"home","full_text":"🌧 18°C","short_text":"🌧 18°C","color":"#87d75f"}],` + "\n",
+	}
+	for format, want := range tests {
+		var out strings.Builder
+		if err := StatusBar(&out, sections, opts, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if out.String() != want {
+			t.Errorf("%s: got %q; want %q", format, out.String(), want)
+		}
+	}
+
+	var out strings.Builder
+	if err := StatusBar(&out, sections, opts, StatusWaybar); err != nil {
+		t.Fatal(err)
+	}
+	var waybar struct{ Text, Tooltip, Class string }
+	if err := json.Unmarshal([]byte(out.String()), &waybar); err != nil {
+		t.Fatalf("waybar output is not JSON: %v", err)
+	}
+	wantTooltip := "Oslo, Norway\nSlight rain, 18.4°C, wind 0.0 km/h\n\n12:00  🌧 18°C  0.5mm\n13:00  🌧 19°C  1.0mm\n\nWed  🌧 9/19°C  2.5mm"
+	if waybar.Text != "🌧 18°C" || waybar.Class != "rain" || waybar.Tooltip != wantTooltip {
+		t.Errorf("waybar = %+v; want tooltip %q", waybar, wantTooltip)
+	}
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+)
+
+// TemplateData is what a user template is executed with, once per location.
+// It is the JSON report entry plus its units, so field names match -format json.
+type TemplateData struct {
+	LocationReport
+	Units ReportUnits
+}
+
+// Template is a user-defined output format based on text/template
+type Template struct {
+	tmpl *template.Template
+	opts Options
+}
+
+// NewTemplate parses text with the helper functions available:
+//
+//	temp, wind, precip      value with its unit, e.g. {{temp .Current.Temperature}}
+//	ctemp                   temperature colored like the text output (if colors are on)
+//	tempUnit, windUnit, precipUnit
+//	describe, icon          condition name and emoji for a weather code
+//	formatTime              {{formatTime "Mon 15:04" .Current.Time}} in the location's time zone
+func NewTemplate(text string, opts Options) (*Template, error) {
+	t := &Template{opts: opts}
+	tmpl, err := template.New("output").Funcs(t.funcs(time.UTC)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	t.tmpl = tmpl
+	return t, nil
+}
+
+// Execute renders the template for each section, ending each with a newline
+func (t *Template) Execute(w io.Writer, sections []Section) error {
+	report := NewReport(sections, t.opts)
+	for i, s := range sections {
+		var buf bytes.Buffer
+		data := TemplateData{LocationReport: report.Forecasts[i], Units: report.Units}
+		if err := t.tmpl.Funcs(t.funcs(sectionZone(s))).Execute(&buf, data); err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		if _, err := buf.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// funcs returns the template helpers, formatting times in zone
+func (t *Template) funcs(zone *time.Location) template.FuncMap {
+	units := t.opts.Units
+	return template.FuncMap{
+		"temp": func(v float64) string {
+			return fmt.Sprintf("%.1f%s", v, forecast.TempUnit(units))
+		},
+		"ctemp": func(v float64) string {
+			if t.opts.Colors {
+				return ColorizeTemp(v, units)
+			}
+			return fmt.Sprintf("%.1f%s", v, forecast.TempUnit(units))
+		},
+		"wind": func(v float64) string {
+			return fmt.Sprintf("%.1f %s", v, forecast.WindUnit(units))
+		},
+		"precip": func(v float64) string {
+			return FormatPrecip(v, forecast.PrecipUnit(units))
+		},
+		"tempUnit":   func() string { return forecast.TempUnit(units) },
+		"windUnit":   func() string { return forecast.WindUnit(units) },
+		"precipUnit": func() string { return forecast.PrecipUnit(units) },
+		"describe":   WeatherDescription,
+		"icon":       WeatherIcon,
+		"formatTime": func(layout, value string) (string, error) {
+			for _, in := range []string{time.RFC3339, "2006-01-02"} {
+				if parsed, err := time.Parse(in, value); err == nil {
+					if in == time.RFC3339 {
+						parsed = parsed.In(zone)
+					}
+					return parsed.Format(layout), nil
+				}
+			}
+			return "", fmt.Errorf("formatTime: cannot parse %q", value)
+		},
+	}
+}
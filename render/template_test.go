@@ -0,0 +1,38 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestTemplate(t *testing.T) {
+	var weather forecast.WeatherData
+	weather.CurrentWeather.Time = "2024-05-01T12:00"
+	weather.CurrentWeather.Temperature = 64.4
+	weather.CurrentWeather.WeatherCode = 2
+	sections := []Section{
+		{Label: "home", Location: geocode.GeoLocation{Name: "New York", Timezone: "America/New_York"}, Weather: weather},
+		{Label: "work", Location: geocode.GeoLocation{Name: "Boston"}, Weather: weather},
+	}
+
+	tmpl, err := NewTemplate(`{{icon .Current.WeatherCode}} {{.Location.Name}} {{temp .Current.Temperature}} {{describe .Current.WeatherCode}} at {{formatTime "15:04" .Current.Time}}`,
+		Options{Units: forecast.UnitImperial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, sections); err != nil {
+		t.Fatal(err)
+	}
+	want := "⛅ New York 64.4°F Partly cloudy at 08:00\n⛅ Boston 64.4°F Partly cloudy at 12:00\n"
+	if out.String() != want {
+		t.Errorf("got %q; want %q", out.String(), want)
+	}
+
+	if _, err := NewTemplate("{{.Current.Temperature", Options{}); err == nil {
+		t.Error("NewTemplate accepted an unterminated action")
+	}
+}
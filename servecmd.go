@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/server"
+)
+
+// Limits for the API server: how long in-flight requests get to finish on
+// shutdown, and how long a client may take to send its request headers
+const (
+	serveShutdownTimeout   = 5 * time.Second
+	serveReadHeaderTimeout = 10 * time.Second
+)
+
+// runServe serves the weather REST API until interrupted
+func runServe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+	units := fs.String("units", "", "Default units (metric or imperial)")
+	var metrics locationList
+	fs.Var(&metrics, "metrics", "Locations exported by /metrics (default: the saved location)")
+	cmd := &Command{retries: -1}
+	fs.StringVar(&cmd.provider, "provider", "", "Weather data provider(s), comma-separated")
+	fs.BoolVar(&cmd.consensus, "consensus", false, "Blend forecasts from all configured providers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config := loadConfig()
+	weatherAPI, err := newServer(config, cmd, forecast.UnitSystem(*units))
+	if err != nil {
+		return err
+	}
+	weatherAPI.Metrics = config.expandLocations(metrics)
+	if len(metrics) == 0 && config.ZipCode != "" {
+		weatherAPI.Metrics = config.expandLocations(splitLocations(config.ZipCode))
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: weatherAPI.Handler(), ReadHeaderTimeout: serveReadHeaderTimeout}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "Serving weather API on http://%s (Ctrl-C to stop)\n", *addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newServer builds the API server from the config, resolving saved location
+// names and remembered choices but never prompting
+func newServer(config Config, cmd *Command, units forecast.UnitSystem) (*server.Server, error) {
+	provider, err := cmd.newProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := configureHTTP(config, cmd); err != nil {
+		return nil, err
+	}
+	if units == "" {
+		units = config.Units
+	}
+	if units == "" {
+		units = forecast.UnitMetric
+	}
+	if units != forecast.UnitMetric && units != forecast.UnitImperial {
+		return nil, fmt.Errorf("unknown unit system %q (use metric or imperial)", units)
+	}
+
+	client := newClient(config)
+	client.Provider = provider
+
+	s := server.New(client)
+	s.Units = units
+	s.Log = os.Stderr
+	s.Locate = func(ctx context.Context, query string) (geocode.GeoLocation, error) {
+		if saved, ok := config.savedLocation(query); ok {
+			return saved.Location, nil
+		}
+		if location, ok := config.Choices[geocode.NormalizeQuery(query)]; ok {
+			return location, nil
+		}
+		return client.Locate(ctx, query)
+	}
+	return s, nil
+}
+
+// printServeHelp lists the serve command in the main help text
+func printServeHelp() {
+	fmt.Printf("  serve [-addr host:port] [-units system] [-metrics loc;loc...]\n")
+	fmt.Printf("        [-provider names] [-consensus]   Serve /v1/current and /v1/forecast as JSON\n")
+	fmt.Printf("                                         and Prometheus metrics on /metrics\n\n")
+}
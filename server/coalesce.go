@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// call is an in-flight or completed group call
+type call struct {
+	done chan struct{} // closed once val and err are set
+	val  interface{}
+	err  error
+}
+
+// wait returns the call's result, or ctx's error if that ends first
+func (c *call) wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// group coalesces concurrent calls with the same key into one execution,
+// so identical requests arriving together cause a single upstream fetch
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do runs fn once for all concurrent callers with the same key and returns
+// its result to each of them. fn runs on its own, so a caller whose ctx ends
+// stops waiting without cutting the call short for the others.
+func (g *group) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return c.wait(ctx)
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go func() {
+		c.val, c.err = fn()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	return c.wait(ctx)
+}
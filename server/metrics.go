@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/render"
+)
+
+// metricsContentType is the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// sample is one labeled value of a gauge
+type sample struct {
+	labels [][2]string // beyond the location label
+	value  float64
+}
+
+// gauge is a metric family derived from a location's forecast
+type gauge struct {
+	name    string
+	help    string
+	samples func(s render.Section) []sample
+}
+
+// gauges lists the exported metrics, in metric units
+var gauges = []gauge{
+	{"weather_temperature_celsius", "Current air temperature.", func(s render.Section) []sample {
+		return []sample{{value: s.Weather.CurrentWeather.Temperature}}
+	}},
+	{"weather_wind_speed_meters_per_second", "Current wind speed.", func(s render.Section) []sample {
+		return []sample{{value: s.Weather.CurrentWeather.WindSpeed / 3.6}}
+	}},
+	{"weather_code", "Current WMO weather code.", func(s render.Section) []sample {
+		return []sample{{value: float64(s.Weather.CurrentWeather.WeatherCode)}}
+	}},
+	{"weather_observation_timestamp_seconds", "Time of the current observation.", func(s render.Section) []sample {
+		t, err := time.Parse("2006-01-02T15:04", s.Weather.CurrentWeather.Time)
+		if err != nil {
+			return nil
+		}
+		return []sample{{value: float64(t.Unix())}}
+	}},
+	{"weather_forecast_precipitation_sum_millimeters", "Forecast precipitation for the day, 0 being today.", func(s render.Section) []sample {
+		var out []sample
+		for i, sum := range s.Weather.Daily.PrecipitationSum {
+			if math.IsNaN(sum) {
+				continue
+			}
+			out = append(out, sample{labels: [][2]string{{"day", strconv.Itoa(i)}}, value: sum})
+		}
+		return out
+	}},
+	{"weather_forecast_temperature_max_celsius", "Forecast high temperature for the day, 0 being today.", func(s render.Section) []sample {
+		var out []sample
+		for i, t := range s.Weather.Daily.TemperatureMax {
+			out = append(out, sample{labels: [][2]string{{"day", strconv.Itoa(i)}}, value: t})
+		}
+		return out
+	}},
+	{"weather_forecast_temperature_min_celsius", "Forecast low temperature for the day, 0 being today.", func(s render.Section) []sample {
+		var out []sample
+		for i, t := range s.Weather.Daily.TemperatureMin {
+			out = append(out, sample{labels: [][2]string{{"day", strconv.Itoa(i)}}, value: t})
+		}
+		return out
+	}},
+}
+
+// handleMetrics serves /metrics for the locations in the location
+// parameters, or s.Metrics without any. Each scrape reads through the
+// forecast cache, so scraping more often than it expires costs no upstream
+// requests.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	queries := r.URL.Query()["location"]
+	if len(queries) == 0 {
+		queries = s.Metrics
+	}
+	if len(queries) == 0 {
+		s.writeError(w, badRequestError("no locations to export; pass location parameters or configure some"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout())
+	defer cancel()
+	sections := make([]render.Section, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			sections[i], errs[i] = s.section(ctx, q, forecast.UnitMetric)
+		}(i, q)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && s.Log != nil {
+			fmt.Fprintf(s.Log, "metrics: %s: %v\n", queries[i], err)
+		}
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	writeMetrics(w, queries, sections, errs)
+}
+
+// writeMetrics writes the gauges of every location that loaded, plus
+// weather_up telling which ones did
+func writeMetrics(w io.Writer, queries []string, sections []render.Section, errs []error) {
+	fmt.Fprintf(w, "# HELP weather_up Whether the last forecast fetch for the location succeeded.\n")
+	fmt.Fprintf(w, "# TYPE weather_up gauge\n")
+	for i, q := range queries {
+		up := 1.0
+		if errs[i] != nil {
+			up = 0
+		}
+		writeSample(w, "weather_up", q, sample{value: up})
+	}
+
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n", g.name, g.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", g.name)
+		for i, q := range queries {
+			if errs[i] != nil {
+				continue
+			}
+			for _, smp := range g.samples(sections[i]) {
+				writeSample(w, g.name, q, smp)
+			}
+		}
+	}
+}
+
+// writeSample writes one exposition line
+func writeSample(w io.Writer, name, location string, smp sample) {
+	labels := []string{`location="` + escapeLabel(location) + `"`}
+	for _, l := range smp.labels {
+		labels = append(labels, l[0]+`="`+escapeLabel(l[1])+`"`)
+	}
+	fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(labels, ","), strconv.FormatFloat(smp.value, 'g', -1, 64))
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{})}
+	close(provider.release)
+	ts := newTestServer(t, provider)
+
+	var body string
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(ts.URL + "/metrics?location=Paris&location=Nowhere")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		body = string(b)
+	}
+
+	for _, want := range []string{
+		"# TYPE weather_temperature_celsius gauge\n",
+		`weather_up{location="Paris"} 1` + "\n",
+		`weather_up{location="Nowhere"} 0` + "\n",
+		`weather_temperature_celsius{location="Paris"} 12` + "\n",
+		`weather_forecast_precipitation_sum_millimeters{location="Paris",day="2"} 2` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `weather_code{location="Nowhere"}`) {
+		t.Errorf("failed location has gauges:\n%s", body)
+	}
+
+	// The second scrape is answered from the cache
+	if n := atomic.LoadInt32(&provider.fetches); n != 1 {
+		t.Errorf("upstream fetches = %d; want 1", n)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got, want := escapeLabel("a\"b\\c\nd"), `a\"b\\c\nd`; got != want {
+		t.Errorf("escapeLabel = %q; want %q", got, want)
+	}
+}
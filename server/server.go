@@ -0,0 +1,313 @@
+// Package server exposes weather lookups as a small JSON REST API backed by
+// the weather client's cache.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
+)
+
+// Defaults for the forecast endpoint's days and hours parameters
+const (
+	DefaultDays  = 7
+	DefaultHours = 24
+)
+
+// DefaultTimeout bounds the geocoding and forecast work behind a request
+const DefaultTimeout = 30 * time.Second
+
+// Server answers /v1/current, /v1/forecast and /metrics requests. Concurrent
+// requests for the same location share a single geocoding and forecast fetch.
+type Server struct {
+	Client  *weather.Client
+	Units   forecast.UnitSystem // default when a request has no units parameter
+	Log     io.Writer           // receives one line per request; nil discards them
+	Metrics []string            // locations exported by /metrics by default
+	Timeout time.Duration       // bounds each request's upstream work; 0 uses DefaultTimeout
+
+	// Locate resolves the location parameter; nil uses Client.Locate
+	Locate func(ctx context.Context, query string) (geocode.GeoLocation, error)
+
+	locations group
+	forecasts group
+}
+
+// New returns a Server using client, in metric units
+func New(client *weather.Client) *Server {
+	return &Server{Client: client, Units: forecast.UnitMetric}
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/current", s.handleCurrent)
+	mux.HandleFunc("/v1/forecast", s.handleForecast)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	return s.logRequests(mux)
+}
+
+// timeout returns the limit for a request's upstream work
+func (s *Server) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultTimeout
+}
+
+// badRequestError is a problem with the request's parameters
+type badRequestError string
+
+func (e badRequestError) Error() string { return string(e) }
+
+// fetched is the shared result of a coalesced forecast fetch
+type fetched struct {
+	weather forecast.WeatherData
+	cached  bool
+}
+
+// handleCurrent serves /v1/current?location=&units=
+func (s *Server) handleCurrent(w http.ResponseWriter, r *http.Request) {
+	section, units, err := s.lookup(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeReport(w, section, render.Options{Units: units})
+}
+
+// handleForecast serves /v1/forecast?location=&days=&hours=&units=
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	days, err := intParam(r, "days", DefaultDays)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	hours, err := intParam(r, "hours", DefaultHours)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	section, units, err := s.lookup(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	section.Weather = trim(section.Weather, days, hours)
+	s.writeReport(w, section, render.Options{Daily: days > 0, Hourly: hours > 0, Units: units})
+}
+
+// lookup parses the request's location and units and fetches its forecast
+func (s *Server) lookup(r *http.Request) (render.Section, forecast.UnitSystem, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return render.Section{}, "", badRequestError("only GET is supported")
+	}
+	query := r.URL.Query().Get("location")
+	if query == "" {
+		return render.Section{}, "", badRequestError("missing location parameter")
+	}
+	units := s.Units
+	if u := r.URL.Query().Get("units"); u != "" {
+		units = forecast.UnitSystem(u)
+		if units != forecast.UnitMetric && units != forecast.UnitImperial {
+			return render.Section{}, "", badRequestError("units must be metric or imperial")
+		}
+	}
+	// Malformed coordinates and plus codes are the client's mistake
+	if _, ok, err := geocode.ParseCoordinates(query); ok && err != nil {
+		return render.Section{}, "", badRequestError(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout())
+	defer cancel()
+	section, err := s.section(ctx, query, units)
+	return section, units, err
+}
+
+// section resolves query and fetches its full forecast, sharing the work
+// with identical requests in flight. The caller stops waiting when ctx ends.
+func (s *Server) section(ctx context.Context, query string, units forecast.UnitSystem) (render.Section, error) {
+	v, err := s.locations.do(ctx, geocode.NormalizeQuery(query), func() (interface{}, error) {
+		ctx, cancel := s.upstreamContext()
+		defer cancel()
+		if s.Locate != nil {
+			return s.Locate(ctx, query)
+		}
+		return s.Client.Locate(ctx, query)
+	})
+	if err != nil {
+		return render.Section{}, err
+	}
+	loc := v.(geocode.GeoLocation)
+
+	opts := forecast.Options{Daily: true, Hourly: true, Units: units}
+	key := fmt.Sprintf("%.4f,%.4f,%s", loc.Latitude, loc.Longitude, units)
+	v, err = s.forecasts.do(ctx, key, func() (interface{}, error) {
+		ctx, cancel := s.upstreamContext()
+		defer cancel()
+		data, cached, err := s.Client.Forecast(ctx, loc, opts)
+		return fetched{data, cached}, err
+	})
+	if err != nil {
+		return render.Section{}, err
+	}
+	f := v.(fetched)
+	return render.Section{Label: query, Location: loc, Weather: f.weather, Cached: f.cached}, nil
+}
+
+// upstreamContext bounds a shared upstream call. It is not tied to any one
+// request, so the call is not cancelled when the first of the waiting
+// clients goes away.
+func (s *Server) upstreamContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout())
+}
+
+// trim keeps the first days of the daily forecast and the hours from the
+// current one onwards, along with any consensus spread
+func trim(weather forecast.WeatherData, days, hours int) forecast.WeatherData {
+	start := 0
+	for start < len(weather.Hourly.Time) && weather.Hourly.Time[start] < weather.CurrentWeather.Time {
+		start++
+	}
+	// Step back to the hour containing the current observation
+	if start > 0 && (start == len(weather.Hourly.Time) || weather.Hourly.Time[start] != weather.CurrentWeather.Time) {
+		start--
+	}
+	hourEnd := clamp(start+hours, len(weather.Hourly.Time))
+	dayEnd := clamp(days, len(weather.Daily.Time))
+
+	d := &weather.Daily
+	d.Time, d.WeatherCode = d.Time[:dayEnd], d.WeatherCode[:dayEnd]
+	d.TemperatureMax, d.TemperatureMin = d.TemperatureMax[:dayEnd], d.TemperatureMin[:dayEnd]
+	d.PrecipitationSum = d.PrecipitationSum[:dayEnd]
+
+	h := &weather.Hourly
+	h.Time, h.WeatherCode = h.Time[start:hourEnd], h.WeatherCode[start:hourEnd]
+	h.Temperature, h.Precipitation = h.Temperature[start:hourEnd], h.Precipitation[start:hourEnd]
+
+	if weather.Spread != nil {
+		spread := *weather.Spread
+		if len(spread.Daily.TemperatureMax) >= dayEnd {
+			spread.Daily.TemperatureMax = spread.Daily.TemperatureMax[:dayEnd]
+			spread.Daily.TemperatureMin = spread.Daily.TemperatureMin[:dayEnd]
+			spread.Daily.PrecipitationSum = spread.Daily.PrecipitationSum[:dayEnd]
+		}
+		if len(spread.Hourly.Temperature) >= hourEnd {
+			spread.Hourly.Temperature = spread.Hourly.Temperature[start:hourEnd]
+			spread.Hourly.Precipitation = spread.Hourly.Precipitation[start:hourEnd]
+		}
+		weather.Spread = &spread
+	}
+	return weather
+}
+
+// clamp limits n to [0, max]
+func clamp(n, max int) int {
+	if n > max {
+		return max
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// intParam parses a non-negative integer query parameter
+func intParam(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, badRequestError(name + " must be a non-negative integer")
+	}
+	return n, nil
+}
+
+// writeReport writes section in the same schema as -format json, letting
+// clients cache it until the forecast expires
+func (s *Server) writeReport(w http.ResponseWriter, section render.Section, opts render.Options) {
+	expires := s.Client.ForecastExpires(section.Location, forecast.Options{Daily: true, Hourly: true, Units: opts.Units})
+	if age := int(time.Until(expires).Seconds()); age > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(age))
+	}
+	writeJSON(w, http.StatusOK, render.NewReport([]render.Section{section}, opts))
+}
+
+// errorBody is the JSON body of a failed request
+type errorBody struct {
+	Error      string   `json:"error"`
+	Candidates []string `json:"candidates,omitempty"` // for ambiguous locations
+}
+
+// writeError maps err to an HTTP status and writes it as JSON
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	body := errorBody{Error: err.Error()}
+	status := http.StatusBadGateway
+
+	var badRequest badRequestError
+	var ambiguous *geocode.AmbiguousError
+	switch {
+	case errors.As(err, &badRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, geocode.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.As(err, &ambiguous):
+		status = http.StatusConflict
+		body.Error = fmt.Sprintf("%q matches several places; add a region or country", ambiguous.Query)
+		for _, c := range ambiguous.Candidates {
+			body.Candidates = append(body.Candidates, c.Summary())
+		}
+	case errors.Is(err, api.ErrRateLimited):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// statusRecorder remembers the status code for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests writes an access log line per request to s.Log
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if s.Log != nil {
+			fmt.Fprintf(s.Log, "%s %s %s %d %s\n", start.Format(time.RFC3339), r.Method, r.URL.RequestURI(),
+				rec.status, time.Since(start).Round(time.Millisecond))
+		}
+	})
+}
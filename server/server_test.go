@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
+)
+
+// slowProvider counts fetches and holds each one until release is closed
+type slowProvider struct {
+	fetches int32
+	release chan struct{}
+}
+
+func (p *slowProvider) Name() string { return "slow" }
+
+func (p *slowProvider) Fetch(ctx context.Context, lat, lon float64, opts forecast.Options) (forecast.WeatherData, error) {
+	atomic.AddInt32(&p.fetches, 1)
+	<-p.release
+
+	var w forecast.WeatherData
+	w.CurrentWeather.Time = "2024-05-01T10:00"
+	w.CurrentWeather.Temperature = 12
+	w.Daily.Time = []string{"2024-05-01", "2024-05-02", "2024-05-03"}
+	w.Daily.WeatherCode = []int{0, 1, 2}
+	w.Daily.TemperatureMax = []float64{15, 16, 17}
+	w.Daily.TemperatureMin = []float64{5, 6, 7}
+	w.Daily.PrecipitationSum = []float64{0, 1, 2}
+	for h := 8; h < 14; h++ {
+		w.Hourly.Time = append(w.Hourly.Time, fmt.Sprintf("2024-05-01T%02d:00", h))
+		w.Hourly.Temperature = append(w.Hourly.Temperature, float64(h))
+		w.Hourly.Precipitation = append(w.Hourly.Precipitation, 0)
+		w.Hourly.WeatherCode = append(w.Hourly.WeatherCode, 0)
+	}
+	return w, nil
+}
+
+func newTestServer(t *testing.T, provider forecast.Provider) *httptest.Server {
+	client := &weather.Client{Provider: provider, Cache: cache.New(t.TempDir(), time.Hour)}
+	s := New(client)
+	s.Locate = func(ctx context.Context, query string) (geocode.GeoLocation, error) {
+		if query == "Nowhere" {
+			return geocode.GeoLocation{}, geocode.ErrNotFound
+		}
+		return geocode.GeoLocation{Name: query, Latitude: 1, Longitude: 2, Timezone: "UTC"}, nil
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestConcurrentRequestsShareFetch(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{})}
+	ts := newTestServer(t, provider)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/v1/current?location=Paris")
+			if err != nil {
+				errs <- err
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d; want 200", resp.StatusCode)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond) // let every request reach the provider
+	close(provider.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&provider.fetches); n != 1 {
+		t.Errorf("upstream fetches = %d; want 1", n)
+	}
+}
+
+func TestForecastTrimsDaysAndHours(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{})}
+	close(provider.release)
+	ts := newTestServer(t, provider)
+
+	resp, err := http.Get(ts.URL + "/v1/forecast?location=Paris&days=2&hours=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var report render.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Forecasts) != 1 {
+		t.Fatalf("forecasts = %d; want 1", len(report.Forecasts))
+	}
+	loc := report.Forecasts[0]
+	if len(loc.Daily) != 2 {
+		t.Errorf("days = %d; want 2", len(loc.Daily))
+	}
+	if len(loc.Hourly) != 3 || loc.Hourly[0].Temperature != 10 {
+		t.Errorf("hourly = %+v; want 3 hours starting at 10:00", loc.Hourly)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{})}
+	close(provider.release)
+	ts := newTestServer(t, provider)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/v1/current", http.StatusBadRequest},
+		{"/v1/current?location=Paris&units=kelvin", http.StatusBadRequest},
+		{"/v1/forecast?location=Paris&days=-1", http.StatusBadRequest},
+		{"/v1/forecast?location=Nowhere", http.StatusNotFound},
+		{"/v1/current?location=91,0", http.StatusBadRequest},
+		{"/v1/current?location=geo:NaN,16.3", http.StatusBadRequest},
+		{"/healthz", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s = %d; want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{})}
+	defer close(provider.release)
+	s := New(&weather.Client{Provider: provider})
+	s.Locate = func(ctx context.Context, query string) (geocode.GeoLocation, error) {
+		return geocode.GeoLocation{Name: query, Latitude: 1, Longitude: 2}, nil
+	}
+	s.Timeout = 50 * time.Millisecond
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/current?location=Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("status = %d; want 504 once the request times out", resp.StatusCode)
+	}
+}
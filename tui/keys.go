@@ -0,0 +1,97 @@
+package tui
+
+// key is a decoded key press
+type key int
+
+const (
+	keyNone key = iota
+	keyQuit
+	keyNextTab
+	keyPrevTab
+	keyTab1
+	keyTab2
+	keyTab3
+	keyNextLocation
+	keyPrevLocation
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyReload
+)
+
+// escapeKeys maps the ANSI sequences sent after ESC [ by common terminals
+var escapeKeys = map[string]key{
+	"A":  keyUp,
+	"B":  keyDown,
+	"C":  keyNextLocation,
+	"D":  keyPrevLocation,
+	"Z":  keyPrevTab,
+	"H":  keyHome,
+	"F":  keyEnd,
+	"1~": keyHome,
+	"4~": keyEnd,
+	"5~": keyPageUp,
+	"6~": keyPageDown,
+}
+
+// plainKeys maps single bytes
+var plainKeys = map[byte]key{
+	'q':  keyQuit,
+	3:    keyQuit, // Ctrl-C, since raw mode turns off signals
+	'\t': keyNextTab,
+	'1':  keyTab1,
+	'2':  keyTab2,
+	'3':  keyTab3,
+	'l':  keyNextLocation,
+	'h':  keyPrevLocation,
+	']':  keyNextLocation,
+	'[':  keyPrevLocation,
+	'k':  keyUp,
+	'j':  keyDown,
+	' ':  keyPageDown,
+	'b':  keyPageUp,
+	'g':  keyHome,
+	'G':  keyEnd,
+	'r':  keyReload,
+}
+
+// parseKeys decodes the bytes of one read from the terminal. A lone ESC
+// quits; unknown bytes and sequences are ignored.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		if b[0] != 0x1b {
+			if k, ok := plainKeys[b[0]]; ok {
+				keys = append(keys, k)
+			}
+			b = b[1:]
+			continue
+		}
+
+		if len(b) == 1 {
+			keys = append(keys, keyQuit)
+			break
+		}
+		if b[1] != '[' && b[1] != 'O' {
+			b = b[1:]
+			continue
+		}
+
+		// The sequence ends at the first byte in @..~ after ESC [
+		end := 2
+		for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+			end++
+		}
+		if end == len(b) {
+			break
+		}
+		if k, ok := escapeKeys[string(b[2:end+1])]; ok {
+			keys = append(keys, k)
+		}
+		b = b[end+1:]
+	}
+	return keys
+}
@@ -0,0 +1,9 @@
+package tui
+
+import "syscall"
+
+// ioctl requests for reading and writing terminal attributes
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
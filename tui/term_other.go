@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("the interactive UI is not supported on this platform")
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errUnsupported
+}
+
+func size(f *os.File) (int, int, error) {
+	return 0, 0, errUnsupported
+}
+
+func notifyResize(c chan<- os.Signal) func() { return func() {} }
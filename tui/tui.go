@@ -0,0 +1,164 @@
+// Package tui is a full-screen interactive terminal UI for browsing the
+// current, hourly and daily forecasts of several locations.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/render"
+)
+
+// App is the interactive UI. Load is called in the background the first
+// time a location is shown, and with fresh set when the user reloads it.
+type App struct {
+	Locations []string // names for the location switcher, in order
+	Load      func(ctx context.Context, name string, fresh bool) (render.Section, error)
+	Options   render.Options
+}
+
+// loaded is the result of a background Load
+type loaded struct {
+	name    string
+	section render.Section
+	err     error
+}
+
+// Run takes over the terminal until the user quits or ctx is cancelled
+func (a *App) Run(ctx context.Context, in, out *os.File) error {
+	if len(a.Locations) == 0 {
+		return errors.New("no locations to show")
+	}
+
+	restore, err := makeRaw(in)
+	if err != nil {
+		return fmt.Errorf("could not set up the terminal: %w", err)
+	}
+	defer restore()
+
+	// Alternate screen with a hidden cursor, undone on the way out
+	fmt.Fprint(out, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(out, "\033[?25h\033[?1049l")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Stop the key reader on the way out; the deadline interrupts its read
+	// where the terminal supports one, otherwise it ends at the next key
+	keys := make(chan []key)
+	stop := make(chan struct{})
+	go readKeys(in, keys, stop)
+	defer func() {
+		close(stop)
+		in.SetReadDeadline(time.Now())
+	}()
+	resized := make(chan os.Signal, 1)
+	defer notifyResize(resized)()
+	results := make(chan loaded)
+
+	entries := map[string]*entry{}
+	load := func(name string, fresh bool) {
+		entries[name] = &entry{loading: true}
+		go func() {
+			section, err := a.Load(ctx, name, fresh)
+			select {
+			case results <- loaded{name, section, err}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	v := &view{locations: a.Locations, opts: a.Options}
+	for {
+		name := a.Locations[v.current]
+		if entries[name] == nil {
+			load(name, false)
+		}
+		v.entry = entries[name]
+		v.width, v.height, err = size(out)
+		if err != nil || v.width <= 0 || v.height <= 0 {
+			v.width, v.height = 80, 24
+		}
+		fmt.Fprint(out, "\033[H"+strings.Join(v.frame(), "\r\n"))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resized:
+		case r := <-results:
+			entries[r.name] = &entry{section: r.section, err: r.err}
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range pressed {
+				if k == keyQuit {
+					return nil
+				}
+				if k == keyReload && !entries[name].loading {
+					load(name, true)
+				}
+				v.handle(k)
+			}
+		}
+	}
+}
+
+// handle applies a navigation key to the view
+func (v *view) handle(k key) {
+	switch k {
+	case keyNextTab:
+		v.tab, v.scroll = (v.tab+1)%len(tabNames), 0
+	case keyPrevTab:
+		v.tab, v.scroll = (v.tab+len(tabNames)-1)%len(tabNames), 0
+	case keyTab1, keyTab2, keyTab3:
+		v.tab, v.scroll = int(k-keyTab1), 0
+	case keyNextLocation:
+		v.current, v.scroll = (v.current+1)%len(v.locations), 0
+	case keyPrevLocation:
+		v.current, v.scroll = (v.current+len(v.locations)-1)%len(v.locations), 0
+	case keyUp:
+		v.scroll--
+	case keyDown:
+		v.scroll++
+	case keyPageUp:
+		v.scroll -= v.bodyHeight()
+	case keyPageDown:
+		v.scroll += v.bodyHeight()
+	case keyHome:
+		v.scroll = 0
+	case keyEnd:
+		v.scroll = 1 << 30 // clamped when the frame is drawn
+	}
+}
+
+// readKeys sends decoded key presses until the input closes or stop is
+// closed, then closes keys
+func readKeys(in *os.File, keys chan<- []key, stop <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			if pressed := parseKeys(buf[:n]); len(pressed) > 0 {
+				select {
+				case keys <- pressed:
+				case <-stop:
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
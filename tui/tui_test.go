@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/render"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := map[string][]key{
+		"q":             {keyQuit},
+		"\x1b":          {keyQuit},
+		"\x1b[A\x1b[B":  {keyUp, keyDown},
+		"\x1b[6~j":      {keyPageDown, keyDown},
+		"\x1b[Z\t2":     {keyPrevTab, keyNextTab, keyTab2},
+		"\x1bOC":        {keyNextLocation},
+		"x\x1b[99~\x03": {keyQuit},
+	}
+	for in, want := range tests {
+		if got := parseKeys([]byte(in)); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseKeys(%q) = %v; want %v", in, got, want)
+		}
+	}
+}
+
+func TestReadKeysStops(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	keys := make(chan []key)
+	stop := make(chan struct{})
+	go readKeys(r, keys, stop)
+	// A key nobody receives must not keep the reader alive after stop
+	w.Write([]byte("j"))
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	r.SetReadDeadline(time.Now())
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-keys:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("readKeys still running after stop")
+		}
+	}
+}
+
+func TestViewScroll(t *testing.T) {
+	var weather forecast.WeatherData
+	for i := 0; i < 48; i++ {
+		weather.Hourly.Time = append(weather.Hourly.Time, fmt.Sprintf("2024-05-0%dT%02d:00", 1+i/24, i%24))
+		weather.Hourly.Temperature = append(weather.Hourly.Temperature, float64(i))
+		weather.Hourly.Precipitation = append(weather.Hourly.Precipitation, 0)
+		weather.Hourly.WeatherCode = append(weather.Hourly.WeatherCode, 0)
+	}
+	v := &view{
+		locations: []string{"home", "work"},
+		entry:     &entry{section: render.Section{Label: "home", Weather: weather}},
+		width:     60,
+		height:    15,
+		opts:      render.Options{Units: forecast.UnitMetric},
+	}
+	v.handle(keyTab2)
+	v.handle(keyEnd)
+
+	lines := v.frame()
+	if len(lines) != v.height {
+		t.Fatalf("frame has %d lines; want %d", len(lines), v.height)
+	}
+	for _, line := range lines {
+		if visibleWidth(line) != v.width {
+			t.Errorf("line %q is not %d wide", line, v.width)
+		}
+	}
+	// Scrolled to the end, the last hour is on the last body line
+	if body := lines[len(lines)-2]; !strings.Contains(body, "Thu 23:00") || !strings.Contains(body, "47.0°C") {
+		t.Errorf("last body line = %q; want the final hour", body)
+	}
+	if v.scroll != 48-v.bodyHeight() {
+		t.Errorf("scroll = %d; want clamped to %d", v.scroll, 48-v.bodyHeight())
+	}
+
+	v.handle(keyNextLocation)
+	if v.current != 1 || v.scroll != 0 {
+		t.Errorf("after switching location: current %d, scroll %d", v.current, v.scroll)
+	}
+}
+
+// visibleWidth counts runes outside ANSI escapes
+func visibleWidth(s string) int {
+	n, inEscape := 0, false
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			inEscape = true
+		case inEscape:
+			inEscape = r != 'm'
+		default:
+			n++
+		}
+	}
+	return n
+}
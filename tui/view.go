@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/render"
+)
+
+// Tabs, in display order
+const (
+	tabCurrent = iota
+	tabHourly
+	tabDaily
+)
+
+var tabNames = []string{"Current", "Hourly", "Daily"}
+
+// Terminal attributes used for highlighting
+const (
+	attrReverse = "\033[7m"
+	attrBold    = "\033[1m"
+	attrDim     = "\033[2m"
+	attrReset   = "\033[0m"
+)
+
+const helpLine = "Tab/1-3 view · ←/→ location · ↑/↓ PgUp/PgDn scroll · r reload · q quit"
+
+// entry is the load state of one location
+type entry struct {
+	section render.Section
+	err     error
+	loading bool
+}
+
+// view is everything needed to draw a frame
+type view struct {
+	tab       int
+	locations []string
+	current   int
+	entry     *entry
+	scroll    int
+	width     int
+	height    int
+	opts      render.Options
+}
+
+// bodyHeight is the number of scrollable lines that fit on screen
+func (v *view) bodyHeight() int {
+	// title, locations, rule, column header and help line
+	if h := v.height - 5; h > 0 {
+		return h
+	}
+	return 1
+}
+
+// frame returns the screen lines, each fitted to the terminal width
+func (v *view) frame() []string {
+	lines := []string{v.tabBar(), v.locationBar(), strings.Repeat("─", v.width)}
+
+	header, rows := v.body()
+	lines = append(lines, header)
+	if last := len(rows) - v.bodyHeight(); v.scroll > last {
+		v.scroll = last
+	}
+	if v.scroll < 0 {
+		v.scroll = 0
+	}
+	for i := v.scroll; i < len(rows) && i < v.scroll+v.bodyHeight(); i++ {
+		lines = append(lines, rows[i])
+	}
+	for len(lines) < v.height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, attrDim+helpLine+attrReset)
+
+	for i := range lines {
+		lines[i] = render.Fit(lines[i], v.width)
+	}
+	return lines
+}
+
+// tabBar is the title with the active tab highlighted
+func (v *view) tabBar() string {
+	bar := attrBold + " go-weather " + attrReset
+	for i, name := range tabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if i == v.tab {
+			label = attrReverse + label + attrReset
+		}
+		bar += " " + label
+	}
+	return bar
+}
+
+// locationBar lists the locations with the current one highlighted
+func (v *view) locationBar() string {
+	parts := make([]string, len(v.locations))
+	for i, name := range v.locations {
+		parts[i] = name
+		if i == v.current {
+			parts[i] = attrReverse + " " + name + " " + attrReset
+		}
+	}
+	return " ◀ " + strings.Join(parts, "  ") + " ▶"
+}
+
+// body returns the fixed column header and the scrollable rows of the tab
+func (v *view) body() (string, []string) {
+	name := v.locations[v.current]
+	switch {
+	case v.entry == nil || v.entry.loading:
+		return "", []string{"  Loading " + name + "..."}
+	case v.entry.err != nil:
+		return "", []string{"  Could not load " + name + ": " + v.entry.err.Error(), "", "  Press r to retry."}
+	}
+
+	s := v.entry.section
+	switch v.tab {
+	case tabHourly:
+		return v.hourly(s)
+	case tabDaily:
+		return v.daily(s)
+	}
+	return "", v.currentRows(s)
+}
+
+// temp formats a temperature, colored when colors are enabled
+func (v *view) temp(t float64) string {
+	if v.opts.Colors {
+		return render.ColorizeTemp(t, v.opts.Units)
+	}
+	return fmt.Sprintf("%.1f%s", t, forecast.TempUnit(v.opts.Units))
+}
+
+func (v *view) currentRows(s render.Section) []string {
+	w := s.Weather
+	zone := s.Location.Zone()
+	rows := []string{
+		"  " + attrBold + s.Location.Summary() + attrReset,
+		"",
+		"  Temperature  " + v.temp(w.CurrentWeather.Temperature),
+	}
+	today := time.Now().In(zone).Format("2006-01-02")
+	for i, day := range w.Daily.Time {
+		if day == today {
+			rows = append(rows, "  High/Low     "+v.temp(w.Daily.TemperatureMax[i])+" / "+v.temp(w.Daily.TemperatureMin[i]))
+		}
+	}
+	rows = append(rows,
+		fmt.Sprintf("  Wind         %.1f %s", w.CurrentWeather.WindSpeed, forecast.WindUnit(v.opts.Units)),
+		"  Condition    "+render.WeatherDescription(w.CurrentWeather.WeatherCode)+" "+render.WeatherIcon(w.CurrentWeather.WeatherCode),
+		"  Observed     "+localTime(w.CurrentWeather.Time, "Mon 15:04", zone),
+	)
+	if w.Source != "" {
+		rows = append(rows, "  Source       "+w.Source)
+	}
+	if s.Cached {
+		rows = append(rows, "", "  "+attrDim+"(cached; press r to reload)"+attrReset)
+	}
+	return rows
+}
+
+func (v *view) hourly(s render.Section) (string, []string) {
+	w := s.Weather
+	zone := s.Location.Zone()
+	precipUnit := forecast.PrecipUnit(v.opts.Units)
+	header := attrBold + fmt.Sprintf("  %-12s %-12s %-10s %s", "Time", "Temperature", "Precip", "Condition") + attrReset
+
+	var rows []string
+	for i, t := range w.Hourly.Time {
+		rows = append(rows, fmt.Sprintf("  %-12s %s %-10s %s",
+			localTime(t, "Mon 15:04", zone),
+			render.Fit(v.temp(w.Hourly.Temperature[i]), 12),
+			render.FormatPrecip(w.Hourly.Precipitation[i], precipUnit),
+			render.WeatherDescription(w.Hourly.WeatherCode[i])))
+	}
+	if len(rows) == 0 {
+		rows = []string{"  No hourly forecast available"}
+	}
+	return header, rows
+}
+
+func (v *view) daily(s render.Section) (string, []string) {
+	w := s.Weather
+	precipUnit := forecast.PrecipUnit(v.opts.Units)
+	header := attrBold + fmt.Sprintf("  %-12s %-12s %-12s %-10s %s", "Date", "Low", "High", "Precip", "Condition") + attrReset
+
+	var rows []string
+	for i, day := range w.Daily.Time {
+		d, _ := time.Parse("2006-01-02", day)
+		rows = append(rows, fmt.Sprintf("  %-12s %s %s %-10s %s",
+			d.Format("Mon Jan 2"),
+			render.Fit(v.temp(w.Daily.TemperatureMin[i]), 12),
+			render.Fit(v.temp(w.Daily.TemperatureMax[i]), 12),
+			render.FormatPrecip(w.Daily.PrecipitationSum[i], precipUnit),
+			render.WeatherDescription(w.Daily.WeatherCode[i])))
+	}
+	if len(rows) == 0 {
+		rows = []string{"  No daily forecast available"}
+	}
+	return header, rows
+}
+
+// localTime formats a model timestamp (UTC) in zone
+func localTime(s, layout string, zone *time.Location) string {
+	t, err := time.Parse("2006-01-02T15:04", s)
+	if err != nil {
+		return s
+	}
+	return t.In(zone).Format(layout)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/tui"
+	"github.com/streek/go-weather/weather"
+)
+
+// runTUI opens the interactive UI over the requested locations followed by
+// the rest of the location book
+func runTUI(ctx context.Context, client *weather.Client, config *Config, queries []string, opts render.Options) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return errors.New("the interactive UI needs a terminal")
+	}
+
+	// Resolve up front, while the ambiguity picker can still use the terminal
+	names := tuiLocations(*config, queries)
+	locations := make(map[string]geocode.GeoLocation, len(names))
+	for _, name := range names {
+		loc, err := locateQuery(ctx, client, config, name, true)
+		if err != nil {
+			return fmt.Errorf("could not get coordinates for %s: %w", name, err)
+		}
+		locations[name] = loc
+	}
+
+	// Every tab is available, so always fetch both series
+	opts.Daily, opts.Hourly = true, true
+	fopts := forecast.Options{Daily: true, Hourly: true, Units: opts.Units}
+
+	app := &tui.App{
+		Locations: names,
+		Options:   opts,
+		Load: func(ctx context.Context, name string, fresh bool) (render.Section, error) {
+			s := render.Section{Label: name, Location: locations[name]}
+			if fresh {
+				client.ForgetForecast(s.Location, fopts)
+			}
+			var err error
+			s.Weather, s.Cached, err = client.Forecast(ctx, s.Location, fopts)
+			return s, err
+		},
+	}
+
+	// Warnings would scribble over the screen
+	client.Log = nil
+	switch p := client.Provider.(type) {
+	case *forecast.Chain:
+		p.Log = nil
+	case *forecast.Consensus:
+		p.Log = nil
+	}
+	return app.Run(ctx, os.Stdin, os.Stdout)
+}
+
+// tuiLocations lists queries, then the saved locations not among them
+func tuiLocations(config Config, queries []string) []string {
+	seen := map[string]bool{}
+	names := append([]string(nil), queries...)
+	for _, q := range queries {
+		seen[strings.ToLower(q)] = true
+	}
+
+	var saved []string
+	for name := range config.Locations {
+		if !seen[name] {
+			saved = append(saved, name)
+		}
+	}
+	sort.Strings(saved)
+	return append(names, saved...)
+}
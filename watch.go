@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+	"github.com/streek/go-weather/render"
+	"github.com/streek/go-weather/weather"
+)
+
+// Bounds for the refresh schedule in watch mode
+const (
+	watchMinInterval = time.Minute     // never refresh more often than this
+	watchRetry       = 2 * time.Minute // wait after a failed refresh
+)
+
+// watchFlag is the -watch flag: "-watch" alone refreshes whenever the cached
+// forecast expires, "-watch=10m" refreshes on a fixed interval
+type watchFlag struct {
+	enabled  bool
+	interval time.Duration
+}
+
+func (w *watchFlag) String() string {
+	if w.interval > 0 {
+		return w.interval.String()
+	}
+	return fmt.Sprint(w.enabled)
+}
+
+func (w *watchFlag) Set(s string) error {
+	switch s {
+	case "true":
+		w.enabled = true
+		return nil
+	case "false":
+		w.enabled = false
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < watchMinInterval {
+		return fmt.Errorf("watch interval must be a duration of at least %s, e.g. 15m", watchMinInterval)
+	}
+	w.enabled, w.interval = true, d
+	return nil
+}
+
+// IsBoolFlag lets -watch be given without a value
+func (w *watchFlag) IsBoolFlag() bool { return true }
+
+// noArgs rejects arguments left over after the flags. Being a bool flag,
+// -watch never takes the next argument, so "-watch 10m" ends up here.
+func noArgs(args []string, watch watchFlag) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if _, err := time.ParseDuration(args[0]); err == nil && watch.enabled {
+		return fmt.Errorf("unexpected argument %q; give the watch interval as -watch=%s", args[0], args[0])
+	}
+	return fmt.Errorf("unexpected argument %q", args[0])
+}
+
+// watcher keeps a report on screen, refreshing it periodically. When a
+// refresh fails the last good data stays up, marked stale.
+type watcher struct {
+	out      io.Writer
+	redraw   bool // clear the screen before each draw
+	bare     bool // write only the report, for status bars; failures go to log
+	log      io.Writer
+	interval time.Duration // fixed refresh interval; 0 follows expires
+	fetch    func(ctx context.Context) ([]render.Section, error)
+	draw     func(w io.Writer, sections []render.Section) error
+	expires  func(sections []render.Section) time.Time // when the data goes stale
+}
+
+// run draws and refreshes until ctx is cancelled
+func (w *watcher) run(ctx context.Context) error {
+	var last []render.Section
+	var updated time.Time
+	for {
+		sections, err := w.fetch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if locationError(err) {
+			return err
+		}
+
+		now := time.Now()
+		var next time.Time
+		if err == nil {
+			last, updated = sections, now
+			next = w.nextRefresh(now, sections)
+		} else {
+			next = now.Add(watchRetry)
+		}
+		if err := w.show(last, updated, next, err); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// locationError reports whether err is a location that cannot be resolved
+// without the user, which no amount of retrying fixes
+func locationError(err error) bool {
+	return errors.Is(err, geocode.ErrNotFound) || errors.As(err, new(*geocode.AmbiguousError))
+}
+
+// nextRefresh picks the time of the next fetch after a successful one
+func (w *watcher) nextRefresh(now time.Time, sections []render.Section) time.Time {
+	next := now.Add(w.interval)
+	if w.interval == 0 {
+		next = w.expires(sections)
+	}
+	if next.Sub(now) < watchMinInterval {
+		next = now.Add(watchMinInterval)
+	}
+	return next
+}
+
+// show draws the last good data and the status footer in one write, so the
+// screen never shows a half-drawn frame
+func (w *watcher) show(sections []render.Section, updated, next time.Time, fetchErr error) error {
+	if w.bare {
+		// The bar keeps showing the last update
+		if fetchErr != nil {
+			fmt.Fprintf(w.log, "Update failed: %v; retrying at %s\n", fetchErr, next.Format("15:04:05"))
+			return nil
+		}
+		return w.draw(w.out, sections)
+	}
+
+	var buf bytes.Buffer
+	if w.redraw {
+		buf.WriteString("\033[H\033[2J")
+	}
+	if sections != nil {
+		if err := w.draw(&buf, sections); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(watchFooter(updated, next, fetchErr))
+	_, err := buf.WriteTo(w.out)
+	return err
+}
+
+// watchFooter describes when the data was fetched and when it refreshes next
+func watchFooter(updated, next time.Time, fetchErr error) string {
+	const clock = "15:04:05"
+	if fetchErr == nil {
+		return fmt.Sprintf("Last updated %s, next refresh %s (Ctrl-C to quit)\n", updated.Format(clock), next.Format(clock))
+	}
+
+	footer := fmt.Sprintf("Update failed at %s: %v\n", time.Now().Format(clock), fetchErr)
+	if !updated.IsZero() {
+		footer += fmt.Sprintf("STALE: showing data from %s, ", updated.Format(clock))
+	}
+	return footer + fmt.Sprintf("retrying at %s (Ctrl-C to quit)\n", next.Format(clock))
+}
+
+// firstExpiry returns when the first of the sections' cached forecasts expires
+func firstExpiry(client *weather.Client, sections []render.Section, opts forecast.Options) time.Time {
+	var first time.Time
+	for _, s := range sections {
+		if t := client.ForecastExpires(s.Location, opts); first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
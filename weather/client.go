@@ -0,0 +1,148 @@
+// Package weather combines geocoding, forecast fetching and caching into a
+// single client that can be used from other Go programs.
+package weather
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+// DefaultCacheDuration is how long forecasts are reused before refetching
+const DefaultCacheDuration = 1 * time.Hour
+
+// DefaultGeocodeCacheDuration is how long resolved locations are reused;
+// places rarely move, so this is much longer than for forecasts
+const DefaultGeocodeCacheDuration = 30 * 24 * time.Hour
+
+// Client looks up locations and their forecasts
+type Client struct {
+	Geocoder *geocode.Geocoder
+	Provider forecast.Provider
+	Cache    *cache.Cache // nil disables caching
+	GeoCache *cache.Cache // resolved locations; nil disables caching
+	Log      io.Writer    // receives non-fatal warnings; nil discards them
+}
+
+// NewClient returns a Client using the public Open-Meteo endpoints and the
+// default cache directory. Set Provider to use another forecast source.
+func NewClient() *Client {
+	return &Client{
+		Geocoder: geocode.NewGeocoder(),
+		Provider: forecast.NewOpenMeteo(),
+		Cache:    cache.New(cache.DefaultDir(), DefaultCacheDuration),
+		GeoCache: cache.New(GeocodeCacheDir(), DefaultGeocodeCacheDuration),
+	}
+}
+
+// GeocodeCacheDir returns the default directory for resolved locations
+func GeocodeCacheDir() string {
+	return filepath.Join(cache.DefaultDir(), "geocode")
+}
+
+// Locate resolves a ZIP/postal code or city name to a GeoLocation. Successful
+// lookups are cached, so repeated queries skip the geocoding request.
+func (c *Client) Locate(ctx context.Context, query string) (geocode.GeoLocation, error) {
+	key := geocodeCacheKey(c.Geocoder.Language, query)
+
+	var location geocode.GeoLocation
+	if c.GeoCache != nil && c.GeoCache.Get(key, &location) {
+		return location, nil
+	}
+
+	location, err := c.Geocoder.Lookup(ctx, query)
+	if err != nil {
+		return geocode.GeoLocation{}, err
+	}
+
+	if c.GeoCache != nil {
+		if err := c.GeoCache.Put(key, location); err != nil {
+			c.warnf("Warning: Failed to cache location: %v\n", err)
+		}
+	}
+	return location, nil
+}
+
+// ForgetLocation drops the cached lookup for query
+func (c *Client) ForgetLocation(query string) error {
+	if c.GeoCache == nil {
+		return nil
+	}
+	return c.GeoCache.Delete(geocodeCacheKey(c.Geocoder.Language, query))
+}
+
+// Forecast returns weather data for loc, reporting whether it came from
+// cache. Daily series cover the calendar days of loc's time zone.
+func (c *Client) Forecast(ctx context.Context, loc geocode.GeoLocation, opts forecast.Options) (forecast.WeatherData, bool, error) {
+	opts.Timezone = loc.Timezone
+	key := forecastCacheKey(c.Provider.Name(), loc.Latitude, loc.Longitude, opts)
+
+	var weather forecast.WeatherData
+	if c.Cache != nil && c.Cache.Get(key, &weather) {
+		return weather, true, nil
+	}
+
+	weather, err := c.Provider.Fetch(ctx, loc.Latitude, loc.Longitude, opts)
+	if err != nil {
+		return forecast.WeatherData{}, false, err
+	}
+	if weather.Source == "" {
+		weather.Source = c.Provider.Name()
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.Put(key, weather); err != nil {
+			// Non-critical error, just log it
+			c.warnf("Warning: Failed to cache weather data: %v\n", err)
+		}
+	}
+	return weather, false, nil
+}
+
+// ForgetForecast drops the cached forecast for loc, so the next Forecast
+// fetches fresh data
+func (c *Client) ForgetForecast(loc geocode.GeoLocation, opts forecast.Options) error {
+	if c.Cache == nil {
+		return nil
+	}
+	opts.Timezone = loc.Timezone
+	return c.Cache.Delete(forecastCacheKey(c.Provider.Name(), loc.Latitude, loc.Longitude, opts))
+}
+
+// ForecastExpires returns when the cached forecast for loc expires, so callers
+// can refresh right then. Without a cache entry it reports time.Now().
+func (c *Client) ForecastExpires(loc geocode.GeoLocation, opts forecast.Options) time.Time {
+	if c.Cache != nil {
+		opts.Timezone = loc.Timezone
+		key := forecastCacheKey(c.Provider.Name(), loc.Latitude, loc.Longitude, opts)
+		if expires, ok := c.Cache.Expires(key); ok {
+			return expires
+		}
+	}
+	return time.Now()
+}
+
+// Generate a cache key from request parameters
+func forecastCacheKey(provider string, lat, lon float64, opts forecast.Options) string {
+	return cache.Key(fmt.Sprintf("%s-%.4f-%.4f-d%v-h%v-u%s-z%s", provider, lat, lon, opts.Daily, opts.Hourly, opts.Units, opts.Timezone))
+}
+
+// Generate a geocode cache key from the normalized query and language
+func geocodeCacheKey(language, query string) string {
+	if language == "" {
+		language = geocode.DefaultLanguage
+	}
+	return cache.Key("geocode-" + language + "-" + geocode.NormalizeQuery(query))
+}
+
+func (c *Client) warnf(format string, args ...interface{}) {
+	if c.Log != nil {
+		fmt.Fprintf(c.Log, format, args...)
+	}
+}
@@ -0,0 +1,85 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/streek/go-weather/api"
+	"github.com/streek/go-weather/cache"
+	"github.com/streek/go-weather/forecast"
+	"github.com/streek/go-weather/geocode"
+)
+
+func TestForecastErrorNotCached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":true,"reason":"Minutely API request limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	openMeteo := forecast.NewOpenMeteo()
+	openMeteo.BaseURL = server.URL
+	openMeteo.HTTP = api.NewClient()
+	openMeteo.HTTP.MaxRetries = 0
+	dir := t.TempDir()
+	client := &Client{Provider: openMeteo, Cache: cache.New(dir, time.Hour)}
+
+	_, _, err := client.Forecast(context.Background(), geocode.GeoLocation{Latitude: 1, Longitude: 2}, forecast.Options{})
+	if !errors.Is(err, api.ErrRateLimited) {
+		t.Fatalf("Forecast error = %v; want rate limited", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("failed response was cached: %d files", len(entries))
+	}
+}
+
+func TestLocateCached(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"results":[{"name":"Paris","latitude":48.85,"longitude":2.35,"country":"France","timezone":"Europe/Paris","elevation":42}]}`))
+	}))
+	defer server.Close()
+
+	geocoder := geocode.NewGeocoder()
+	geocoder.BaseURL = server.URL
+	client := &Client{Geocoder: geocoder, GeoCache: cache.New(t.TempDir(), time.Hour)}
+	ctx := context.Background()
+
+	for _, q := range []string{"Paris", " paris "} {
+		loc, err := client.Locate(ctx, q)
+		if err != nil {
+			t.Fatalf("Locate(%q): %v", q, err)
+		}
+		if loc.Timezone != "Europe/Paris" || loc.Elevation != 42 {
+			t.Errorf("Locate(%q) = %+v; want timezone and elevation", q, loc)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("made %d geocoding requests; want 1", requests)
+	}
+
+	// Another language is a separate entry
+	geocoder.Language = "fr"
+	if _, err := client.Locate(ctx, "Paris"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("made %d geocoding requests after changing language; want 2", requests)
+	}
+
+	if err := client.ForgetLocation("Paris"); err != nil {
+		t.Fatal(err)
+	}
+	client.Locate(ctx, "Paris")
+	if requests != 3 {
+		t.Errorf("made %d geocoding requests after forgetting; want 3", requests)
+	}
+}